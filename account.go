@@ -0,0 +1,217 @@
+package sharesies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+var ErrUnknownAccount = errors.New("account not available to this login")
+var ErrAccountMismatch = errors.New("quote was made for another account")
+
+// Account issues requests acting as one of the accounts available to the
+// login, such as a joint, kids or business account
+type Account struct {
+	s  *Sharesies
+	id string
+}
+
+// Accounts returns the accounts the authenticated login can act as
+func (s *Sharesies) Accounts() ([]*UserSimple, error) {
+	sess, err := s.currentSession()
+	if err != nil {
+		return nil, err
+	}
+
+	return sess.profile.UserList, nil
+}
+
+// SelectAccount sets the account used by the Sharesies methods, the first
+// account of the login is used until one is selected
+func (s *Sharesies) SelectAccount(accountID string) error {
+	sess, err := s.currentSession()
+	if err != nil {
+		return err
+	}
+
+	if _, err := sess.accountID(accountID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.accountID = accountID
+	s.mu.Unlock()
+
+	return nil
+}
+
+// As returns an Account whose methods act as accountID, regardless of the selected account
+func (s *Sharesies) As(accountID string) *Account {
+	return &Account{s: s, id: accountID}
+}
+
+// account returns the selected Account
+func (s *Sharesies) account() *Account {
+	return &Account{s: s}
+}
+
+// ID returns the ID of the account requests are issued for
+func (a *Account) ID() (string, error) {
+	return a.actingAsID()
+}
+
+func (a *Account) actingAsID() (string, error) {
+	sess, err := a.s.currentSession()
+	if err != nil {
+		return "", err
+	}
+
+	id := a.id
+	if id == "" {
+		a.s.mu.Lock()
+		id = a.s.accountID
+		a.s.mu.Unlock()
+	}
+
+	return sess.accountID(id)
+}
+
+// quotedAs returns the account a acts as, which must be quotedID when the quote recorded one
+func (a *Account) quotedAs(quotedID string) (string, error) {
+	actingAsID, err := a.actingAsID()
+	if err != nil {
+		return "", err
+	}
+
+	if quotedID != "" && quotedID != actingAsID {
+		return "", fmt.Errorf("%w: quoted for %s, acting as %s", ErrAccountMismatch, quotedID, actingAsID)
+	}
+
+	return actingAsID, nil
+}
+
+// accountID returns id if the login can act as it, or the first account when id is empty
+func (t *tokenSession) accountID(id string) (string, error) {
+	if len(t.profile.UserList) == 0 {
+		return "", ErrNotAuthenticated
+	}
+
+	if id == "" {
+		return t.profile.UserList[0].ID, nil
+	}
+
+	for _, u := range t.profile.UserList {
+		if u.ID == id {
+			return id, nil
+		}
+	}
+
+	return "", ErrUnknownAccount
+}
+
+// CostBuy return Cost to buy stocks from the NZX Market
+func (a *Account) CostBuy(ctx context.Context, fundId string, amount Decimal) (*CostBuyResponse, error) {
+	currencyAmount := amount.Round(2)
+	return a.costBuy(ctx, fundId, &OrderBuy{Type: OrderTypeDollarMarket, CurrencyAmount: &currencyAmount})
+}
+
+// CostBuyShares return Cost to buy exactly shareAmount shares of a fund
+func (a *Account) CostBuyShares(ctx context.Context, fundId string, shareAmount Shares) (*CostBuyResponse, error) {
+	shares := shareAmount.Round(SharesPlaces)
+	return a.costBuy(ctx, fundId, &OrderBuy{Type: OrderTypeShareMarket, ShareAmount: &shares})
+}
+
+func (a *Account) costBuy(ctx context.Context, fundId string, o *OrderBuy) (*CostBuyResponse, error) {
+	actingAsID, err := a.actingAsID()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &CostBuyResponse{ActingAsID: actingAsID, IdempotencyKey: uuid.NewString()}
+	cr := &CostBuyRequest{
+		FundID:     fundId,
+		ActingAsID: actingAsID,
+		Order:      o,
+	}
+
+	err = a.s.authRequest(ctx, http.MethodPost, a.s.endpoints().CostBuy, false, cr, r)
+	return r, err
+}
+
+// Buy purchase stocks from the NZX Market. Buying the same costBuy again
+// reuses its idempotency key so the order is never placed twice.
+func (a *Account) Buy(ctx context.Context, costBuy *CostBuyResponse) (*ProfileResponse, error) {
+	actingAsID, err := a.quotedAs(costBuy.ActingAsID)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ProfileResponse{}
+	if costBuy.IdempotencyKey == "" {
+		costBuy.IdempotencyKey = uuid.NewString()
+	}
+
+	br := &CreateBuyRequest{
+		FundID:           costBuy.FundID,
+		ActingAsID:       actingAsID,
+		Order:            costBuy.Request,
+		PaymentBreakdown: costBuy.PaymentBreakdown,
+		IdempotencyKey:   costBuy.IdempotencyKey,
+		ExpectedFee:      costBuy.ExpectedFee,
+	}
+
+	err = a.s.authRequest(ctx, http.MethodPost, a.s.endpoints().CreateBuy, false, br, r)
+	return r, err
+}
+
+// CostSell return Cost to sell shareAmount shares of a fund
+func (a *Account) CostSell(ctx context.Context, fundId string, shareAmount Shares) (*CostSellResponse, error) {
+	shares := shareAmount.Round(SharesPlaces)
+	return a.costSell(ctx, fundId, &OrderSell{Type: OrderTypeShareMarket, ShareAmount: &shares})
+}
+
+// CostSellDollars return Cost to sell as many shares of a fund as amount is worth
+func (a *Account) CostSellDollars(ctx context.Context, fundId string, amount Decimal) (*CostSellResponse, error) {
+	currencyAmount := amount.Round(2)
+	return a.costSell(ctx, fundId, &OrderSell{Type: OrderTypeDollarMarket, CurrencyAmount: &currencyAmount})
+}
+
+func (a *Account) costSell(ctx context.Context, fundId string, o *OrderSell) (*CostSellResponse, error) {
+	actingAsID, err := a.actingAsID()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &CostSellResponse{ActingAsID: actingAsID, IdempotencyKey: uuid.NewString()}
+	sr := &CostSellRequest{FundID: fundId, ActingAsID: actingAsID, Order: o}
+
+	err = a.s.authRequest(ctx, http.MethodPost, a.s.endpoints().CostSell, false, sr, r)
+	return r, err
+}
+
+// Sell sells stocks quoted by CostSell. Selling the same sellBuy again
+// reuses its idempotency key so the order is never placed twice.
+func (a *Account) Sell(ctx context.Context, sellBuy *CostSellResponse) (*ProfileResponse, error) {
+	actingAsID, err := a.quotedAs(sellBuy.ActingAsID)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ProfileResponse{}
+	if sellBuy.IdempotencyKey == "" {
+		sellBuy.IdempotencyKey = uuid.NewString()
+	}
+
+	sr := CreateSellRequest{
+		FundID:         sellBuy.FundID,
+		ActingAsID:     actingAsID,
+		Order:          sellBuy.Request,
+		IdempotencyKey: sellBuy.IdempotencyKey,
+	}
+
+	err = a.s.authRequest(ctx, http.MethodPost, a.s.endpoints().CreateSell, false, sr, r)
+	return r, err
+}
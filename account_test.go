@@ -0,0 +1,137 @@
+package sharesies_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/sharesiestest"
+)
+
+func newAccountsServer(t *testing.T) (*sharesiestest.Account, *sharesies.Sharesies) {
+	srv, _, _ := newFakeServer(t)
+
+	kids, err := srv.AddAccount("username", "Kids")
+	assert.Nil(t, err)
+	kids.Deposit("nzd", sharesies.NewDecimal(100, 0))
+
+	s, _ := sharesies.NewWithOptions(srv.Options())
+	_, err = s.Authenticate(context.Background(), &sharesies.Credentials{Username: "username", Password: "password"})
+	assert.Nil(t, err)
+
+	return kids, s
+}
+
+func Test_Accounts(t *testing.T) {
+	kids, s := newAccountsServer(t)
+
+	accounts, err := s.Accounts()
+
+	assert.Nil(t, err)
+	assert.Len(t, accounts, 2)
+	assert.True(t, accounts[0].Primary)
+	assert.Equal(t, kids.ID, accounts[1].ID)
+	assert.Equal(t, "Kids", accounts[1].PreferredName)
+}
+
+func Test_As(t *testing.T) {
+	kids, s := newAccountsServer(t)
+	ctx := context.Background()
+
+	costBuy, err := s.As(kids.ID).CostBuy(ctx, fakeFundID, sharesies.NewDecimal(20, 0))
+	assert.Nil(t, err)
+
+	p, err := s.As(kids.ID).Buy(ctx, costBuy)
+	assert.Nil(t, err)
+	assert.Equal(t, kids.ID, p.User.ID)
+	assert.Equal(t, "80.00", kids.Balance("nzd").String())
+
+	id, _ := s.As(kids.ID).ID()
+	assert.Equal(t, kids.ID, id)
+}
+
+func Test_As_UnknownAccount(t *testing.T) {
+	_, s := newAccountsServer(t)
+
+	_, err := s.As("someone-else").CostBuy(context.Background(), fakeFundID, sharesies.NewDecimal(20, 0))
+
+	assert.Equal(t, sharesies.ErrUnknownAccount, err)
+}
+
+func Test_SelectAccount(t *testing.T) {
+	kids, s := newAccountsServer(t)
+	ctx := context.Background()
+
+	assert.Equal(t, sharesies.ErrUnknownAccount, s.SelectAccount("someone-else"))
+	assert.Nil(t, s.SelectAccount(kids.ID))
+
+	costBuy, err := s.CostBuy(ctx, fakeFundID, sharesies.NewDecimal(30, 0))
+	assert.Nil(t, err)
+
+	_, err = s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+	assert.Equal(t, "70.00", kids.Balance("nzd").String())
+}
+
+func Test_Buy_OtherAccountQuote(t *testing.T) {
+	kids, s := newAccountsServer(t)
+	ctx := context.Background()
+
+	costBuy, err := s.As(kids.ID).CostBuy(ctx, fakeFundID, sharesies.NewDecimal(20, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, kids.ID, costBuy.ActingAsID)
+
+	_, err = s.Buy(ctx, costBuy)
+	assert.True(t, errors.Is(err, sharesies.ErrAccountMismatch), err)
+	assert.Equal(t, "100", kids.Balance("nzd").String())
+
+	_, err = s.As(kids.ID).Buy(ctx, costBuy)
+	assert.Nil(t, err)
+	assert.Equal(t, "80.00", kids.Balance("nzd").String())
+}
+
+func Test_BuySharesThenSellDollars(t *testing.T) {
+	_, acc, s := newFakeServer(t)
+	ctx := context.Background()
+
+	costBuy, err := s.CostBuyShares(ctx, fakeFundID, sharesies.MustParseShares("10"))
+	assert.Nil(t, err)
+	assert.Equal(t, "20.10", costBuy.TotalCost.String())
+
+	_, err = s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.000000", acc.Shares(fakeFundID).String())
+	assert.Equal(t, "79.90", acc.Balance("nzd").String())
+
+	costSell, err := s.CostSellDollars(ctx, fakeFundID, sharesies.NewDecimal(5, 0))
+	assert.Nil(t, err)
+
+	_, err = s.Sell(ctx, costSell)
+	assert.Nil(t, err)
+	assert.Equal(t, "7.500000", acc.Shares(fakeFundID).String())
+	assert.Equal(t, "84.87", acc.Balance("nzd").StringFixed(2))
+
+	_, err = s.CostSellDollars(ctx, fakeFundID, sharesies.NewDecimal(50, 0))
+	assert.True(t, sharesies.IsInsufficientShares(err))
+}
+
+func Test_CostBuyShares_CostSellDollars_Round(t *testing.T) {
+	_, _, s := newFakeServer(t)
+	ctx := context.Background()
+
+	costBuy, err := s.CostBuyShares(ctx, fakeFundID, sharesies.MustParseShares("1.1234567"))
+	assert.Nil(t, err)
+	assert.Equal(t, sharesies.OrderTypeShareMarket, costBuy.Request.Type)
+	assert.Equal(t, "1.123457", costBuy.Request.ShareAmount.String())
+
+	_, err = s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+
+	costSell, err := s.CostSellDollars(ctx, fakeFundID, sharesies.MustParseDecimal("1.005"))
+	assert.Nil(t, err)
+	assert.Equal(t, sharesies.OrderTypeDollarMarket, costSell.Request.Type)
+	assert.Equal(t, "1.01", costSell.Request.CurrencyAmount.String())
+}
@@ -0,0 +1,181 @@
+package sharesies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var ErrInvalidAutoinvest = errors.New("invalid autoinvest order")
+
+// Autoinvest is the configuration of an autoinvest order, either
+// Allocations or a PremadeOrderID is required
+type Autoinvest struct {
+	// Allocations are the percentage of Amount invested in each fund, adding up to 100
+	Allocations    []Allocations
+	PremadeOrderID string
+	Amount         Decimal
+	// Interval is one of AutoinvestIntervalWeekly, AutoinvestIntervalFortnightly or AutoinvestIntervalMonthly
+	Interval string
+	// StartDate is the first day the order runs, the next interval when zero
+	StartDate Date
+}
+
+// Validate checks the autoinvest order before it is sent
+func (o *Autoinvest) Validate() error {
+	switch o.Interval {
+	case AutoinvestIntervalWeekly, AutoinvestIntervalFortnightly, AutoinvestIntervalMonthly:
+	default:
+		return fmt.Errorf("%w: unknown interval %q", ErrInvalidAutoinvest, o.Interval)
+	}
+
+	if o.Amount.Sign() <= 0 {
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidAutoinvest)
+	}
+
+	if (len(o.Allocations) == 0) == (o.PremadeOrderID == "") {
+		return fmt.Errorf("%w: either allocations or a premade order is required", ErrInvalidAutoinvest)
+	}
+
+	total := Decimal{}
+	funds := map[string]bool{}
+	for _, a := range o.Allocations {
+		if a.FundID == "" || a.Allocation.Sign() <= 0 || funds[a.FundID] {
+			return fmt.Errorf("%w: allocations need distinct funds and positive percentages", ErrInvalidAutoinvest)
+		}
+
+		funds[a.FundID] = true
+		total = total.Add(a.Allocation)
+	}
+
+	if len(o.Allocations) > 0 && !total.Equal(NewDecimal(100, 0)) {
+		return fmt.Errorf("%w: allocations add up to %s%%", ErrInvalidAutoinvest, total)
+	}
+
+	return nil
+}
+
+// Autoinvest returns the configuration of the order, to be changed and sent with UpdateAutoinvest
+func (o *AutoinvestOrder) Autoinvest() *Autoinvest {
+	a := &Autoinvest{
+		Allocations:    append([]Allocations(nil), o.Allocations...),
+		PremadeOrderID: o.PremadeOrderID,
+		Amount:         o.Amount,
+		Interval:       o.Interval,
+	}
+
+	// a premade order sets its own allocations
+	if a.PremadeOrderID != "" {
+		a.Allocations = nil
+	}
+
+	return a
+}
+
+// CreateAutoinvest sets up the autoinvest order of the account
+func (s *Sharesies) CreateAutoinvest(ctx context.Context, o *Autoinvest) (*AutoinvestOrder, error) {
+	return s.account().CreateAutoinvest(ctx, o)
+}
+
+// UpdateAutoinvest replaces the allocations, amount and interval of the autoinvest order
+func (s *Sharesies) UpdateAutoinvest(ctx context.Context, o *Autoinvest) (*AutoinvestOrder, error) {
+	return s.account().UpdateAutoinvest(ctx, o)
+}
+
+// PauseAutoinvest stops the autoinvest order from running until resumed
+func (s *Sharesies) PauseAutoinvest(ctx context.Context) (*AutoinvestOrder, error) {
+	return s.account().PauseAutoinvest(ctx)
+}
+
+// ResumeAutoinvest restarts a paused autoinvest order
+func (s *Sharesies) ResumeAutoinvest(ctx context.Context) (*AutoinvestOrder, error) {
+	return s.account().ResumeAutoinvest(ctx)
+}
+
+// DeleteAutoinvest removes the autoinvest order
+func (s *Sharesies) DeleteAutoinvest(ctx context.Context) error {
+	return s.account().DeleteAutoinvest(ctx)
+}
+
+// PremadeAutoinvestOrders returns the premade portfolios an autoinvest order can follow
+func (s *Sharesies) PremadeAutoinvestOrders(ctx context.Context) ([]*PremadeOrder, error) {
+	return s.account().PremadeAutoinvestOrders(ctx)
+}
+
+// CreateAutoinvest sets up the autoinvest order of the account
+func (a *Account) CreateAutoinvest(ctx context.Context, o *Autoinvest) (*AutoinvestOrder, error) {
+	return a.saveAutoinvest(ctx, a.s.endpoints().CreateAutoinvest, o)
+}
+
+// UpdateAutoinvest replaces the allocations, amount and interval of the autoinvest order
+func (a *Account) UpdateAutoinvest(ctx context.Context, o *Autoinvest) (*AutoinvestOrder, error) {
+	return a.saveAutoinvest(ctx, a.s.endpoints().UpdateAutoinvest, o)
+}
+
+// PauseAutoinvest stops the autoinvest order from running until resumed
+func (a *Account) PauseAutoinvest(ctx context.Context) (*AutoinvestOrder, error) {
+	return a.autoinvestAction(ctx, a.s.endpoints().PauseAutoinvest)
+}
+
+// ResumeAutoinvest restarts a paused autoinvest order
+func (a *Account) ResumeAutoinvest(ctx context.Context) (*AutoinvestOrder, error) {
+	return a.autoinvestAction(ctx, a.s.endpoints().ResumeAutoinvest)
+}
+
+// DeleteAutoinvest removes the autoinvest order
+func (a *Account) DeleteAutoinvest(ctx context.Context) error {
+	_, err := a.autoinvestAction(ctx, a.s.endpoints().DeleteAutoinvest)
+	return err
+}
+
+// PremadeAutoinvestOrders returns the premade portfolios an autoinvest order can follow
+func (a *Account) PremadeAutoinvestOrders(ctx context.Context) ([]*PremadeOrder, error) {
+	actingAsID, err := a.actingAsID()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &PremadeOrdersResponse{}
+	err = a.s.authRequest(ctx, http.MethodPost, a.s.endpoints().PremadeAutoinvest, false, &AutoinvestActionRequest{ActingAsID: actingAsID}, r)
+	return r.Orders, err
+}
+
+func (a *Account) saveAutoinvest(ctx context.Context, url string, o *Autoinvest) (*AutoinvestOrder, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	actingAsID, err := a.actingAsID()
+	if err != nil {
+		return nil, err
+	}
+
+	req := &AutoinvestRequest{
+		ActingAsID:     actingAsID,
+		Allocations:    o.Allocations,
+		PremadeOrderID: o.PremadeOrderID,
+		Amount:         o.Amount,
+		Interval:       o.Interval,
+	}
+
+	if !o.StartDate.IsZero() {
+		start := o.StartDate
+		req.StartDate = &start
+	}
+
+	r := &AutoinvestResponse{}
+	err = a.s.authRequest(ctx, http.MethodPost, url, false, req, r)
+	return r.Order, err
+}
+
+func (a *Account) autoinvestAction(ctx context.Context, url string) (*AutoinvestOrder, error) {
+	actingAsID, err := a.actingAsID()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &AutoinvestResponse{}
+	err = a.s.authRequest(ctx, http.MethodPost, url, false, &AutoinvestActionRequest{ActingAsID: actingAsID}, r)
+	return r.Order, err
+}
@@ -0,0 +1,120 @@
+package sharesies_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+func Test_Autoinvest_Validate(t *testing.T) {
+	valid := func() *sharesies.Autoinvest {
+		return &sharesies.Autoinvest{
+			Allocations: []sharesies.Allocations{
+				{FundID: "a", Allocation: sharesies.NewDecimal(60, 0)},
+				{FundID: "b", Allocation: sharesies.NewDecimal(40, 0)},
+			},
+			Amount:   sharesies.NewDecimal(50, 0),
+			Interval: sharesies.AutoinvestIntervalWeekly,
+		}
+	}
+
+	assert.Nil(t, valid().Validate())
+	assert.Nil(t, (&sharesies.Autoinvest{PremadeOrderID: "p", Amount: sharesies.NewDecimal(50, 0), Interval: sharesies.AutoinvestIntervalMonthly}).Validate())
+
+	cases := map[string]func(o *sharesies.Autoinvest){
+		"interval":  func(o *sharesies.Autoinvest) { o.Interval = "daily" },
+		"amount":    func(o *sharesies.Autoinvest) { o.Amount = sharesies.Decimal{} },
+		"both":      func(o *sharesies.Autoinvest) { o.PremadeOrderID = "p" },
+		"neither":   func(o *sharesies.Autoinvest) { o.Allocations = nil },
+		"total":     func(o *sharesies.Autoinvest) { o.Allocations[1].Allocation = sharesies.NewDecimal(30, 0) },
+		"duplicate": func(o *sharesies.Autoinvest) { o.Allocations[1].FundID = "a" },
+	}
+
+	for name, change := range cases {
+		o := valid()
+		change(o)
+		assert.True(t, errors.Is(o.Validate(), sharesies.ErrInvalidAutoinvest), name)
+	}
+}
+
+func Test_AutoinvestOrder_Autoinvest(t *testing.T) {
+	o := &sharesies.AutoinvestOrder{
+		Allocations:    []sharesies.Allocations{{FundID: "a", Allocation: sharesies.NewDecimal(100, 0)}},
+		Amount:         sharesies.NewDecimal(20, 0),
+		Interval:       sharesies.AutoinvestIntervalFortnightly,
+		PremadeOrderID: "p",
+	}
+
+	a := o.Autoinvest()
+	assert.Nil(t, a.Allocations)
+	assert.Equal(t, "p", a.PremadeOrderID)
+	assert.Nil(t, a.Validate())
+}
+
+func Test_Autoinvest(t *testing.T) {
+	srv, acc, s := newFakeServer(t)
+	ctx := context.Background()
+
+	srv.AddPremadeOrder(&sharesies.PremadeOrder{
+		ID:          "premade-nz",
+		Name:        "Kiwi Classics",
+		Allocations: []sharesies.Allocations{{FundID: fakeFundID, Allocation: sharesies.NewDecimal(100, 0)}},
+	})
+
+	_, err := s.PauseAutoinvest(ctx)
+	assert.True(t, sharesies.IsNoAutoinvest(err))
+
+	o, err := s.CreateAutoinvest(ctx, &sharesies.Autoinvest{
+		Allocations: []sharesies.Allocations{{FundID: fakeFundID, Allocation: sharesies.NewDecimal(100, 0)}},
+		Amount:      sharesies.NewDecimal(25, 0),
+		Interval:    sharesies.AutoinvestIntervalWeekly,
+		StartDate:   sharesies.Date{Year: 2030, Month: time.January, Day: 7},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, sharesies.AutoinvestStateActive, o.State)
+	assert.Equal(t, "2030-01-07", o.NextDate)
+
+	premade, err := s.PremadeAutoinvestOrders(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, premade, 1)
+
+	update := o.Autoinvest()
+	update.Allocations, update.PremadeOrderID = nil, premade[0].ID
+	update.Interval = sharesies.AutoinvestIntervalMonthly
+	o, err = s.UpdateAutoinvest(ctx, update)
+	assert.Nil(t, err)
+	assert.Equal(t, "premade-nz", o.PremadeOrderID)
+	assert.Equal(t, sharesies.AutoinvestIntervalMonthly, o.Interval)
+
+	o, err = s.PauseAutoinvest(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, sharesies.AutoinvestStatePaused, o.State)
+
+	p, err := s.Profile(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, sharesies.AutoinvestStatePaused, p.AutoinvestOrder.State)
+
+	o, err = s.ResumeAutoinvest(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, sharesies.AutoinvestStateActive, o.State)
+
+	assert.Nil(t, s.DeleteAutoinvest(ctx))
+	assert.Nil(t, acc.Autoinvest())
+}
+
+func Test_Autoinvest_InvalidFund(t *testing.T) {
+	_, _, s := newFakeServer(t)
+
+	_, err := s.CreateAutoinvest(context.Background(), &sharesies.Autoinvest{
+		Allocations: []sharesies.Allocations{{FundID: "unknown", Allocation: sharesies.NewDecimal(100, 0)}},
+		Amount:      sharesies.NewDecimal(25, 0),
+		Interval:    sharesies.AutoinvestIntervalWeekly,
+	})
+
+	assert.True(t, sharesies.IsInvalidFund(err))
+}
@@ -0,0 +1,80 @@
+package sharesies_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// Run with -race to detect unsynchronised access to the session
+func Test_Concurrent_Requests(t *testing.T) {
+	_, _, s := newFakeServer(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := s.Instruments(ctx, &sharesies.InstrumentsRequest{Page: 1, Perpage: 10, Query: "air"})
+			errs <- err
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			_, err := s.CostBuy(ctx, fakeFundID, sharesies.NewDecimal(10, 0))
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.Nil(t, err)
+	}
+}
+
+func Test_Concurrent_ReAuthenticate(t *testing.T) {
+	srv, _, _ := newFakeServer(t)
+	ctx := context.Background()
+
+	srv.SetTokenTTL(-time.Minute)
+
+	s, _ := sharesies.NewWithOptions(srv.Options())
+	_, err := s.Authenticate(ctx, &sharesies.Credentials{Username: "username", Password: "password"})
+	assert.Nil(t, err)
+
+	srv.SetTokenTTL(time.Hour)
+	srv.Delay("/api/identity/reauthenticate", 200*time.Millisecond)
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			<-start
+
+			_, err := s.Instruments(ctx, &sharesies.InstrumentsRequest{Page: 1, Perpage: 10})
+			assert.Nil(t, err)
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, 1, srv.Requests("/api/identity/reauthenticate"))
+}
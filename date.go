@@ -0,0 +1,124 @@
+package sharesies
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Date is a calendar day encoded as "2006-01-02", its zero value is the unset date
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NZ is the New Zealand time zone, the one Sharesies dates such as limit
+// order expiries are in
+var NZ = loadNZ()
+
+func loadNZ() *time.Location {
+	loc, err := time.LoadLocation("Pacific/Auckland")
+	if err != nil {
+		return time.FixedZone("NZST", 12*60*60)
+	}
+
+	return loc
+}
+
+// Today returns the current calendar day in New Zealand
+func Today() Date {
+	return NewDate(time.Now().In(NZ))
+}
+
+// NewDate returns the calendar day of t in its location
+func NewDate(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// ParseDate parses a date such as "2021-06-30"
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, err
+	}
+
+	return NewDate(t), nil
+}
+
+// In returns the start of the day in loc
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// AddDays returns the date n days after d
+func (d Date) AddDays(n int) Date {
+	return NewDate(d.In(time.UTC).AddDate(0, 0, n))
+}
+
+// Before reports whether d is before o
+func (d Date) Before(o Date) bool {
+	return d.In(time.UTC).Before(o.In(time.UTC))
+}
+
+// After reports whether d is after o
+func (d Date) After(o Date) bool {
+	return d.In(time.UTC).After(o.In(time.UTC))
+}
+
+// IsZero reports whether d is unset
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON accepts "2006-01-02" dates as well as full RFC 3339 timestamps
+func (d *Date) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*d = Date{}
+		return nil
+	}
+
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+
+	if s == "" {
+		*d = Date{}
+		return nil
+	}
+
+	if len(s) > len(dateLayout) {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+
+		*d = NewDate(t)
+		return nil
+	}
+
+	v, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+
+	*d = v
+	return nil
+}
@@ -0,0 +1,35 @@
+// Package dca runs dollar-cost averaging plans, buying a fixed amount split
+// across funds on a schedule.
+//
+// A Scheduler keeps the run history in a HistoryStore, so a run missed while
+// the program was down is caught up once when it starts again, and waits for
+// the NZX to open when asked to:
+//
+//	nz, _ := time.LoadLocation("Pacific/Auckland")
+//	schedule, err := dca.Weekly(time.Monday, 10, 0, nz)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	history, _ := dca.NewFileHistoryStore("dca.json")
+//
+//	sched := &dca.Scheduler{
+//		Client:  s,
+//		History: history,
+//		Plans: []*dca.Plan{{
+//			ID:     "kiwi",
+//			Amount: sharesies.NewDecimal(100, 0),
+//			Allocations: []dca.Allocation{
+//				{FundID: airNZ, Weight: sharesies.NewDecimal(1, 0)},
+//				{FundID: fisherPaykel, Weight: sharesies.NewDecimal(1, 0)},
+//			},
+//			Schedule: schedule,
+//			Start:    time.Date(2022, time.January, 1, 0, 0, 0, 0, nz),
+//		}},
+//		WaitForMarket: true,
+//	}
+//
+//	log.Fatal(sched.Run(ctx))
+//
+// With DryRun set orders are only quoted with CostBuy, nothing is bought and
+// the history is left untouched.
+package dca
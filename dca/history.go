@@ -0,0 +1,206 @@
+package dca
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// Run is one execution of a plan
+type Run struct {
+	PlanID string `json:"plan_id"`
+	// Scheduled is the slot of the schedule the run is for
+	Scheduled time.Time   `json:"scheduled"`
+	Started   time.Time   `json:"started"`
+	Finished  time.Time   `json:"finished,omitempty"`
+	DryRun    bool        `json:"dry_run,omitempty"`
+	Purchases []*Purchase `json:"purchases"`
+}
+
+// Purchase is the order of one allocation of a run
+type Purchase struct {
+	FundID    string            `json:"fund_id"`
+	Amount    sharesies.Decimal `json:"amount"`
+	Fee       sharesies.Decimal `json:"fee"`
+	TotalCost sharesies.Decimal `json:"total_cost"`
+	// OrderID is empty when the order placed could not be told apart, see sharesies.ProfileResponse.PlacedOrder
+	OrderID string `json:"order_id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Failed reports whether any purchase of the run failed
+func (r *Run) Failed() bool {
+	for _, p := range r.Purchases {
+		if p.Error != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TotalCost returns the cost of every purchase quoted, fees included
+func (r *Run) TotalCost() sharesies.Decimal {
+	total := sharesies.Decimal{}
+	for _, p := range r.Purchases {
+		total = total.Add(p.TotalCost)
+	}
+
+	return total
+}
+
+func (r *Run) clone() *Run {
+	c := *r
+	c.Purchases = make([]*Purchase, 0, len(r.Purchases))
+	for _, p := range r.Purchases {
+		cp := *p
+		c.Purchases = append(c.Purchases, &cp)
+	}
+
+	return &c
+}
+
+// HistoryStore persists the runs of plans between restarts
+type HistoryStore interface {
+	// Last returns the latest run of plan, nil if it never ran
+	Last(ctx context.Context, planID string) (*Run, error)
+	// Save stores run, replacing any run of the same plan and slot
+	Save(ctx context.Context, run *Run) error
+}
+
+// MemoryHistoryStore keeps the history in memory
+type MemoryHistoryStore struct {
+	mu   sync.Mutex
+	runs []*Run
+}
+
+// NewMemoryHistoryStore returns an empty MemoryHistoryStore
+func NewMemoryHistoryStore() *MemoryHistoryStore {
+	return &MemoryHistoryStore{}
+}
+
+func (m *MemoryHistoryStore) Last(ctx context.Context, planID string) (*Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return last(m.runs, planID), nil
+}
+
+func (m *MemoryHistoryStore) Save(ctx context.Context, run *Run) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.runs = replace(m.runs, run.clone())
+	return nil
+}
+
+// Runs returns every run saved, in the order they were first saved
+func (m *MemoryHistoryStore) Runs() []*Run {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*Run(nil), m.runs...)
+}
+
+// FileHistoryStore saves the history to a JSON file
+type FileHistoryStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileHistoryStore returns a FileHistoryStore writing to path, created on the first Save
+func NewFileHistoryStore(path string) (*FileHistoryStore, error) {
+	f := &FileHistoryStore{path: path}
+	if _, err := f.load(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *FileHistoryStore) Last(ctx context.Context, planID string) (*Run, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	runs, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return last(runs, planID), nil
+}
+
+func (f *FileHistoryStore) Save(ctx context.Context, run *Run) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	runs, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(replace(runs, run), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// Replace the file atomically so a crash never loses the history
+	return os.Rename(tmp.Name(), f.path)
+}
+
+func (f *FileHistoryStore) load() ([]*Run, error) {
+	b, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []*Run{}
+	return runs, json.Unmarshal(b, &runs)
+}
+
+func last(runs []*Run, planID string) *Run {
+	var latest *Run
+	for _, r := range runs {
+		if r.PlanID == planID && (latest == nil || r.Scheduled.After(latest.Scheduled)) {
+			latest = r
+		}
+	}
+
+	return latest
+}
+
+func replace(runs []*Run, run *Run) []*Run {
+	for i, r := range runs {
+		if r.PlanID == run.PlanID && r.Scheduled.Equal(run.Scheduled) {
+			runs[i] = run
+			return runs
+		}
+	}
+
+	return append(runs, run)
+}
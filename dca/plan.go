@@ -0,0 +1,78 @@
+package dca
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+var ErrInvalidPlan = errors.New("invalid plan")
+
+// Allocation is the share of a plan's amount invested in a fund
+type Allocation struct {
+	FundID string
+	// Weight relative to the other allocations, 1 and 1 split the amount in half
+	Weight sharesies.Decimal
+}
+
+// Plan buys Amount split across Allocations on every run of Schedule
+type Plan struct {
+	// ID identifies the plan in the run history
+	ID          string
+	Amount      sharesies.Decimal
+	Allocations []Allocation
+	Schedule    Schedule
+	// Start is when the plan begins, the first run is the first one after it.
+	// It is required so a process started afresh for every run, such as by
+	// cron, still reaches the first slot.
+	Start time.Time
+}
+
+// Validate checks the plan can be run
+func (p *Plan) Validate() error {
+	switch {
+	case p.ID == "":
+		return fmt.Errorf("%w: missing id", ErrInvalidPlan)
+	case p.Amount.Sign() <= 0:
+		return fmt.Errorf("%w: %s: amount must be positive", ErrInvalidPlan, p.ID)
+	case len(p.Allocations) == 0:
+		return fmt.Errorf("%w: %s: no allocations", ErrInvalidPlan, p.ID)
+	case p.Schedule == nil:
+		return fmt.Errorf("%w: %s: missing schedule", ErrInvalidPlan, p.ID)
+	case p.Start.IsZero():
+		return fmt.Errorf("%w: %s: missing start", ErrInvalidPlan, p.ID)
+	}
+
+	for _, a := range p.Allocations {
+		if a.FundID == "" || a.Weight.Sign() <= 0 {
+			return fmt.Errorf("%w: %s: allocations need a fund and a positive weight", ErrInvalidPlan, p.ID)
+		}
+	}
+
+	return nil
+}
+
+// Split returns the amount to buy of each allocation, in cents, the last
+// allocation gets the cents left over by rounding down the others
+func (p *Plan) Split() []sharesies.Decimal {
+	total := sharesies.Decimal{}
+	for _, a := range p.Allocations {
+		total = total.Add(a.Weight)
+	}
+
+	amounts := make([]sharesies.Decimal, len(p.Allocations))
+	left := p.Amount
+	for i, a := range p.Allocations {
+		if i == len(p.Allocations)-1 {
+			amounts[i] = left
+			break
+		}
+
+		amounts[i] = p.Amount.Mul(a.Weight).Div(total, 4).Truncate(2)
+		left = left.Sub(amounts[i])
+	}
+
+	return amounts
+}
@@ -0,0 +1,121 @@
+package dca
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule decides when a plan runs
+type Schedule interface {
+	// Next returns the first run strictly after t
+	Next(t time.Time) time.Time
+}
+
+type weekly struct {
+	day          time.Weekday
+	hour, minute int
+	loc          *time.Location
+}
+
+// Weekly runs every week on day at hour:minute in loc
+func Weekly(day time.Weekday, hour, minute int, loc *time.Location) (Schedule, error) {
+	if day < time.Sunday || day > time.Saturday {
+		return nil, fmt.Errorf("%w: weekday %d out of range", ErrInvalidSchedule, day)
+	}
+
+	if err := validTime(hour, minute); err != nil {
+		return nil, err
+	}
+
+	return &weekly{day: day, hour: hour, minute: minute, loc: location(loc)}, nil
+}
+
+func (w *weekly) Next(t time.Time) time.Time {
+	t = t.In(w.loc)
+	for i := 0; ; i++ {
+		next := time.Date(t.Year(), t.Month(), t.Day()+i, w.hour, w.minute, 0, 0, w.loc)
+		if next.Weekday() == w.day && next.After(t) {
+			return next
+		}
+	}
+}
+
+type fortnightly struct {
+	first time.Time
+}
+
+// Fortnightly runs every 14 days from first, at the same wall clock time
+func Fortnightly(first time.Time) Schedule {
+	return &fortnightly{first: first}
+}
+
+func (f *fortnightly) Next(t time.Time) time.Time {
+	if t.Before(f.first) {
+		return f.first
+	}
+
+	k := int(t.Sub(f.first).Hours() / 24 / 14)
+	for {
+		next := f.first.AddDate(0, 0, 14*k)
+		if next.After(t) {
+			return next
+		}
+		k++
+	}
+}
+
+type monthly struct {
+	day          int
+	hour, minute int
+	loc          *time.Location
+}
+
+// Monthly runs every month on day at hour:minute in loc, on the last day of
+// months shorter than day
+func Monthly(day, hour, minute int, loc *time.Location) (Schedule, error) {
+	if day < 1 || day > 31 {
+		return nil, fmt.Errorf("%w: day %d out of range 1-31", ErrInvalidSchedule, day)
+	}
+
+	if err := validTime(hour, minute); err != nil {
+		return nil, err
+	}
+
+	return &monthly{day: day, hour: hour, minute: minute, loc: location(loc)}, nil
+}
+
+func (m *monthly) Next(t time.Time) time.Time {
+	t = t.In(m.loc)
+	for i := 0; ; i++ {
+		first := time.Date(t.Year(), t.Month()+time.Month(i), 1, 0, 0, 0, 0, m.loc)
+
+		day := m.day
+		if last := first.AddDate(0, 1, -1).Day(); day > last {
+			day = last
+		}
+
+		next := time.Date(first.Year(), first.Month(), day, m.hour, m.minute, 0, 0, m.loc)
+		if next.After(t) {
+			return next
+		}
+	}
+}
+
+func validTime(hour, minute int) error {
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return fmt.Errorf("%w: time %02d:%02d out of range", ErrInvalidSchedule, hour, minute)
+	}
+
+	return nil
+}
+
+func location(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.Local
+	}
+
+	return loc
+}
@@ -0,0 +1,110 @@
+package dca_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/dca"
+)
+
+func at(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func Test_Weekly(t *testing.T) {
+	s, err := dca.Weekly(time.Monday, 10, 0, time.UTC)
+	assert.Nil(t, err)
+
+	// Wednesday 5 January 2022
+	assert.Equal(t, at(2022, time.January, 10, 10, 0), s.Next(at(2022, time.January, 5, 12, 0)))
+	assert.Equal(t, at(2022, time.January, 17, 10, 0), s.Next(at(2022, time.January, 10, 10, 0)))
+	assert.Equal(t, at(2022, time.January, 10, 10, 0), s.Next(at(2022, time.January, 10, 9, 59)))
+
+	_, err = dca.Weekly(time.Weekday(7), 10, 0, time.UTC)
+	assert.True(t, errors.Is(err, dca.ErrInvalidSchedule))
+
+	_, err = dca.Weekly(time.Monday, 24, 0, time.UTC)
+	assert.True(t, errors.Is(err, dca.ErrInvalidSchedule))
+}
+
+func Test_Fortnightly(t *testing.T) {
+	first := at(2022, time.January, 3, 9, 0)
+	s := dca.Fortnightly(first)
+
+	assert.Equal(t, first, s.Next(at(2021, time.December, 1, 0, 0)))
+	assert.Equal(t, at(2022, time.January, 17, 9, 0), s.Next(first))
+	assert.Equal(t, at(2022, time.February, 14, 9, 0), s.Next(at(2022, time.February, 1, 0, 0)))
+}
+
+func Test_Monthly(t *testing.T) {
+	s, err := dca.Monthly(31, 9, 30, time.UTC)
+	assert.Nil(t, err)
+
+	assert.Equal(t, at(2022, time.January, 31, 9, 30), s.Next(at(2022, time.January, 1, 0, 0)))
+	assert.Equal(t, at(2022, time.February, 28, 9, 30), s.Next(at(2022, time.January, 31, 9, 30)))
+	assert.Equal(t, at(2022, time.March, 31, 9, 30), s.Next(at(2022, time.February, 28, 9, 30)))
+
+	_, err = dca.Monthly(0, 9, 30, time.UTC)
+	assert.True(t, errors.Is(err, dca.ErrInvalidSchedule))
+}
+
+func Test_ParseCron(t *testing.T) {
+	cases := []struct {
+		expr string
+		from time.Time
+		next time.Time
+	}{
+		{"0 10 * * 1-5", at(2022, time.January, 7, 11, 0), at(2022, time.January, 10, 10, 0)},
+		{"30 9 1,15 * *", at(2022, time.January, 2, 0, 0), at(2022, time.January, 15, 9, 30)},
+		{"*/15 * * * *", at(2022, time.January, 1, 0, 7), at(2022, time.January, 1, 0, 15)},
+		{"0 9-17/4 * * *", at(2022, time.January, 1, 10, 0), at(2022, time.January, 1, 13, 0)},
+		{"0 0 */2 * 1", at(2022, time.January, 3, 0, 0), at(2022, time.January, 17, 0, 0)},
+		{"0 0 29 2 *", at(2022, time.January, 1, 0, 0), at(2024, time.February, 29, 0, 0)},
+		{"0 12 1 * 0", at(2022, time.January, 3, 0, 0), at(2022, time.January, 9, 12, 0)},
+		{"0 0 * * 7", at(2022, time.January, 3, 0, 0), at(2022, time.January, 9, 0, 0)},
+		{"@monthly", at(2022, time.January, 3, 0, 0), at(2022, time.February, 1, 0, 0)},
+	}
+
+	for _, c := range cases {
+		s, err := dca.ParseCron(c.expr, time.UTC)
+		assert.Nil(t, err, c.expr)
+		assert.Equal(t, c.next, s.Next(c.from), c.expr)
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := dca.ParseCron(expr, time.UTC)
+		assert.True(t, errors.Is(err, dca.ErrInvalidCron), expr)
+	}
+}
+
+func Test_Plan(t *testing.T) {
+	p := &dca.Plan{
+		ID:     "plan",
+		Amount: sharesies.NewDecimal(100, 0),
+		Allocations: []dca.Allocation{
+			{FundID: "a", Weight: sharesies.NewDecimal(1, 0)},
+			{FundID: "b", Weight: sharesies.NewDecimal(1, 0)},
+			{FundID: "c", Weight: sharesies.NewDecimal(1, 0)},
+		},
+		Schedule: dca.Fortnightly(at(2022, time.January, 3, 10, 0)),
+		Start:    at(2022, time.January, 1, 0, 0),
+	}
+
+	assert.Nil(t, p.Validate())
+
+	split := p.Split()
+	assert.Equal(t, "33.33", split[0].String())
+	assert.Equal(t, "33.33", split[1].String())
+	assert.Equal(t, "33.34", split[2].String())
+
+	p.Start = time.Time{}
+	assert.True(t, errors.Is(p.Validate(), dca.ErrInvalidPlan))
+
+	p.Start = at(2022, time.January, 1, 0, 0)
+	p.Allocations[1].Weight = sharesies.Decimal{}
+	assert.True(t, errors.Is(p.Validate(), dca.ErrInvalidPlan))
+}
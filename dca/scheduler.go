@@ -0,0 +1,252 @@
+package dca
+
+import (
+	"context"
+	"time"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// DefaultMarketRecheck is how long a Scheduler waiting for the NZX waits
+// when the profile does not say when it next opens
+const DefaultMarketRecheck = 15 * time.Minute
+
+// Client places the orders of a plan, *sharesies.Sharesies satisfies it
+type Client interface {
+	Profile(ctx context.Context) (*sharesies.ProfileResponse, error)
+	CostBuy(ctx context.Context, fundId string, amount sharesies.Decimal) (*sharesies.CostBuyResponse, error)
+	Buy(ctx context.Context, costBuy *sharesies.CostBuyResponse) (*sharesies.ProfileResponse, error)
+}
+
+// Scheduler runs Plans when they are due, it must not be used concurrently
+type Scheduler struct {
+	Client Client
+	Plans  []*Plan
+	// History defaults to a MemoryHistoryStore
+	History HistoryStore
+	// Clock defaults to SystemClock
+	Clock Clock
+	// DryRun only quotes the orders with CostBuy, runs are kept in memory instead of History
+	DryRun bool
+	// WaitForMarket holds due runs until the NZX is open
+	WaitForMarket bool
+	// OnRun is called after every run
+	OnRun func(*Run)
+
+	dryRuns  map[string]time.Time
+	nextOpen time.Time
+}
+
+// RunDue runs every plan with a slot due since its last run. Missed slots
+// are caught up by a single run for the latest of them.
+func (s *Scheduler) RunDue(ctx context.Context) ([]*Run, error) {
+	now := s.clock().Now()
+	s.nextOpen = time.Time{}
+
+	var profile *sharesies.ProfileResponse
+	runs := []*Run{}
+	for _, p := range s.Plans {
+		if err := p.Validate(); err != nil {
+			return runs, err
+		}
+
+		slot, due, err := s.due(ctx, p, now)
+		if err != nil {
+			return runs, err
+		}
+
+		if !due {
+			continue
+		}
+
+		if s.WaitForMarket {
+			if profile == nil {
+				if profile, err = s.Client.Profile(ctx); err != nil {
+					return runs, err
+				}
+			}
+
+			if !profile.NzxIsOpen {
+				s.nextOpen = now.Add(DefaultMarketRecheck)
+				if profile.NzxNextOpen != nil && profile.NzxNextOpen.Time().After(now) {
+					s.nextOpen = profile.NzxNextOpen.Time()
+				}
+				continue
+			}
+		}
+
+		r, err := s.run(ctx, p, slot)
+		if err != nil {
+			return runs, err
+		}
+
+		runs = append(runs, r)
+		if s.OnRun != nil {
+			s.OnRun(r)
+		}
+	}
+
+	return runs, nil
+}
+
+// Run runs the plans as they fall due until ctx is done
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if _, err := s.RunDue(ctx); err != nil {
+			return err
+		}
+
+		wake, err := s.next(ctx)
+		if err != nil {
+			return err
+		}
+
+		if wake.IsZero() {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock().After(wake.Sub(s.clock().Now())):
+		}
+	}
+}
+
+// next returns when the next plan falls due, zero if none ever will
+func (s *Scheduler) next(ctx context.Context) (time.Time, error) {
+	now := s.clock().Now()
+	wake := s.nextOpen
+	for _, p := range s.Plans {
+		last, err := s.last(ctx, p)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		// a slot already due is waiting for the market to open
+		if n := p.Schedule.Next(last); n.After(now) && (wake.IsZero() || n.Before(wake)) {
+			wake = n
+		}
+	}
+
+	return wake, nil
+}
+
+// last returns the slot of the latest run of p, or its start
+func (s *Scheduler) last(ctx context.Context, p *Plan) (time.Time, error) {
+	last := p.Start
+
+	r, err := s.history().Last(ctx, p.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if r != nil && r.Scheduled.After(last) {
+		last = r.Scheduled
+	}
+
+	if dry, ok := s.dryRuns[p.ID]; s.DryRun && ok && dry.After(last) {
+		last = dry
+	}
+
+	return last, nil
+}
+
+// due returns the latest slot of p not after now that has not run yet
+func (s *Scheduler) due(ctx context.Context, p *Plan, now time.Time) (time.Time, bool, error) {
+	last, err := s.last(ctx, p)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	slot := p.Schedule.Next(last)
+	if slot.IsZero() || slot.After(now) {
+		return time.Time{}, false, nil
+	}
+
+	for {
+		n := p.Schedule.Next(slot)
+		if n.IsZero() || n.After(now) {
+			return slot, true, nil
+		}
+		slot = n
+	}
+}
+
+// run buys the allocations of p for slot. The slot is saved before ordering
+// so a crash part way through is never retried into a second purchase.
+func (s *Scheduler) run(ctx context.Context, p *Plan, slot time.Time) (*Run, error) {
+	r := &Run{PlanID: p.ID, Scheduled: slot, Started: s.clock().Now(), DryRun: s.DryRun}
+
+	// the orders known before buying tell the ones placed by the run apart
+	var before *sharesies.ProfileResponse
+	if !s.DryRun {
+		var err error
+		if before, err = s.Client.Profile(ctx); err != nil {
+			return nil, err
+		}
+
+		if err := s.history().Save(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, amount := range p.Split() {
+		purchase := &Purchase{FundID: p.Allocations[i].FundID, Amount: amount}
+		r.Purchases = append(r.Purchases, purchase)
+
+		cost, err := s.Client.CostBuy(ctx, purchase.FundID, amount)
+		if err != nil {
+			purchase.Error = err.Error()
+			continue
+		}
+		purchase.Fee, purchase.TotalCost = cost.ExpectedFee, cost.TotalCost
+
+		if s.DryRun {
+			continue
+		}
+
+		profile, err := s.Client.Buy(ctx, cost)
+		if err != nil {
+			purchase.Error = err.Error()
+			continue
+		}
+
+		if o := profile.PlacedOrder(before, purchase.FundID, sharesies.OrderSideBuy); o != nil {
+			purchase.OrderID = o.ID
+		}
+		before = profile
+	}
+	r.Finished = s.clock().Now()
+
+	if s.DryRun {
+		if s.dryRuns == nil {
+			s.dryRuns = map[string]time.Time{}
+		}
+		s.dryRuns[p.ID] = slot
+		return r, nil
+	}
+
+	return r, s.history().Save(ctx, r)
+}
+
+func (s *Scheduler) history() HistoryStore {
+	if s.History == nil {
+		s.History = NewMemoryHistoryStore()
+	}
+
+	return s.History
+}
+
+func (s *Scheduler) clock() Clock {
+	if s.Clock == nil {
+		return SystemClock
+	}
+
+	return s.Clock
+}
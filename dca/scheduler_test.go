@@ -0,0 +1,235 @@
+package dca_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/dca"
+	"github.com/deividfortuna/sharesies/sharesiestest"
+)
+
+const (
+	airNZ   = "b8b7ef58-b270-4762-a256-9d68aebc3e23"
+	spark   = "4c4d6b8a-2f1e-4c1b-9a64-5d7c9f3a2e10"
+	planID  = "weekly"
+	balance = 1000
+)
+
+// clock is a Clock whose After moves the time forward instead of waiting
+type clock struct {
+	mu       sync.Mutex
+	now      time.Time
+	advanced func(now time.Time)
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *clock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	if c.advanced != nil {
+		c.advanced(c.now)
+	}
+
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func (c *clock) set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = t
+}
+
+func newScheduler(t *testing.T) (*sharesiestest.Server, *sharesiestest.Account, *dca.Scheduler, *clock) {
+	srv := sharesiestest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddInstrument(&sharesies.Company{ID: airNZ, Symbol: "AIR", Marketprice: sharesies.MustParseDecimal("2.00"), Exchange: "NZX", Exchangecountry: "nzl"})
+	srv.AddInstrument(&sharesies.Company{ID: spark, Symbol: "SPK", Marketprice: sharesies.MustParseDecimal("5.00"), Exchange: "NZX", Exchangecountry: "nzl"})
+
+	acc := srv.AddUser("username", "password")
+	acc.Deposit("nzd", sharesies.NewDecimal(balance, 0))
+
+	s, err := sharesies.NewWithOptions(srv.Options())
+	assert.Nil(t, err)
+
+	_, err = s.Authenticate(context.Background(), &sharesies.Credentials{Username: "username", Password: "password"})
+	assert.Nil(t, err)
+
+	weekly, err := dca.Weekly(time.Monday, 10, 0, time.UTC)
+	assert.Nil(t, err)
+
+	c := &clock{now: at(2022, time.January, 3, 9, 0)}
+	sched := &dca.Scheduler{
+		Client:  s,
+		History: dca.NewMemoryHistoryStore(),
+		Clock:   c,
+		Plans: []*dca.Plan{{
+			ID:     planID,
+			Amount: sharesies.NewDecimal(100, 0),
+			Allocations: []dca.Allocation{
+				{FundID: airNZ, Weight: sharesies.NewDecimal(3, 0)},
+				{FundID: spark, Weight: sharesies.NewDecimal(1, 0)},
+			},
+			Schedule: weekly,
+			Start:    at(2022, time.January, 1, 0, 0),
+		}},
+	}
+
+	return srv, acc, sched, c
+}
+
+func Test_Scheduler_RunDue(t *testing.T) {
+	_, acc, sched, c := newScheduler(t)
+	ctx := context.Background()
+
+	runs, err := sched.RunDue(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, runs)
+
+	c.set(at(2022, time.January, 3, 10, 0))
+	runs, err = sched.RunDue(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, runs, 1)
+	assert.False(t, runs[0].Failed())
+	assert.Equal(t, at(2022, time.January, 3, 10, 0), runs[0].Scheduled)
+	assert.True(t, sharesies.NewDecimal(75, 0).Equal(runs[0].Purchases[0].Amount))
+	assert.NotEmpty(t, runs[0].Purchases[0].OrderID)
+	assert.Equal(t, "37.3", acc.Shares(airNZ).Round(1).String())
+	assert.Equal(t, "900.00", acc.Balance("nzd").String())
+
+	runs, err = sched.RunDue(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, runs)
+}
+
+func Test_Scheduler_CatchUpOnce(t *testing.T) {
+	_, acc, sched, c := newScheduler(t)
+	ctx := context.Background()
+
+	// down for three weekly runs
+	c.set(at(2022, time.January, 20, 8, 0))
+
+	runs, err := sched.RunDue(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, runs, 1)
+	assert.Equal(t, at(2022, time.January, 17, 10, 0), runs[0].Scheduled)
+
+	runs, err = sched.RunDue(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, runs)
+	assert.Equal(t, "900.00", acc.Balance("nzd").String())
+}
+
+func Test_Scheduler_DryRun(t *testing.T) {
+	_, acc, sched, c := newScheduler(t)
+	ctx := context.Background()
+
+	sched.DryRun = true
+	c.set(at(2022, time.January, 3, 10, 0))
+
+	runs, err := sched.RunDue(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, runs, 1)
+	assert.True(t, runs[0].DryRun)
+	assert.False(t, runs[0].TotalCost().IsZero())
+	assert.True(t, sharesies.NewDecimal(balance, 0).Equal(acc.Balance("nzd")))
+
+	runs, _ = sched.RunDue(ctx)
+	assert.Empty(t, runs)
+
+	last, err := sched.History.Last(ctx, planID)
+	assert.Nil(t, err)
+	assert.Nil(t, last)
+}
+
+func Test_Scheduler_WaitForMarket(t *testing.T) {
+	srv, acc, sched, c := newScheduler(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opens := at(2022, time.January, 4, 10, 0)
+	srv.SetNZXOpen(false, opens)
+	sched.WaitForMarket = true
+	c.set(at(2022, time.January, 3, 10, 0))
+
+	runs, err := sched.RunDue(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, runs)
+	assert.True(t, sharesies.NewDecimal(balance, 0).Equal(acc.Balance("nzd")))
+
+	sched.OnRun = func(r *dca.Run) {
+		assert.Equal(t, opens, c.Now())
+		assert.Equal(t, at(2022, time.January, 3, 10, 0), r.Scheduled)
+		cancel()
+	}
+	c.advanced = func(now time.Time) {
+		if !now.Before(opens) {
+			srv.SetNZXOpen(true, time.Time{})
+		}
+	}
+
+	assert.Equal(t, context.Canceled, sched.Run(ctx))
+	assert.Equal(t, "900.00", acc.Balance("nzd").String())
+}
+
+func Test_Scheduler_Run(t *testing.T) {
+	_, acc, sched, c := newScheduler(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheduled := []time.Time{}
+	sched.OnRun = func(r *dca.Run) {
+		scheduled = append(scheduled, r.Scheduled)
+		assert.Equal(t, r.Scheduled, c.Now())
+		if len(scheduled) == 3 {
+			cancel()
+		}
+	}
+
+	assert.Equal(t, context.Canceled, sched.Run(ctx))
+	assert.Equal(t, []time.Time{at(2022, time.January, 3, 10, 0), at(2022, time.January, 10, 10, 0), at(2022, time.January, 17, 10, 0)}, scheduled)
+	assert.Equal(t, "700.00", acc.Balance("nzd").String())
+}
+
+func Test_FileHistoryStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "dca.json")
+
+	store, err := dca.NewFileHistoryStore(path)
+	assert.Nil(t, err)
+
+	last, err := store.Last(ctx, planID)
+	assert.Nil(t, err)
+	assert.Nil(t, last)
+
+	first := &dca.Run{PlanID: planID, Scheduled: at(2022, time.January, 3, 10, 0)}
+	assert.Nil(t, store.Save(ctx, first))
+	assert.Nil(t, store.Save(ctx, &dca.Run{PlanID: planID, Scheduled: at(2022, time.January, 10, 10, 0)}))
+
+	first.Purchases = []*dca.Purchase{{FundID: airNZ, Amount: sharesies.NewDecimal(75, 0)}}
+	assert.Nil(t, store.Save(ctx, first))
+
+	reopened, err := dca.NewFileHistoryStore(path)
+	assert.Nil(t, err)
+
+	last, err = reopened.Last(ctx, planID)
+	assert.Nil(t, err)
+	assert.Equal(t, at(2022, time.January, 10, 10, 0), last.Scheduled.UTC())
+}
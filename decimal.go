@@ -0,0 +1,434 @@
+package sharesies
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// SharesPlaces is the number of decimal places Sharesies accepts for share amounts
+const SharesPlaces = 6
+
+var ErrInvalidDecimal = errors.New("invalid decimal")
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+var ten = big.NewInt(10)
+
+// Decimal is an exact base 10 number, its zero value is 0.
+// Decimal values are immutable, every operation returns a new value.
+type Decimal struct {
+	value *big.Int
+	scale int32
+}
+
+// NewDecimal returns value × 10^-scale, e.g. NewDecimal(1050, 2) is 10.50
+func NewDecimal(value int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{value: new(big.Int).Mul(big.NewInt(value), pow10(-scale))}
+	}
+
+	return Decimal{value: big.NewInt(value), scale: scale}
+}
+
+// NewDecimalFromFloat returns the shortest Decimal representing f, it panics if f is NaN or infinite
+func NewDecimalFromFloat(f float64) Decimal {
+	return MustParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// ParseDecimal parses a plain decimal number such as "-12.3400"
+func ParseDecimal(s string) (Decimal, error) {
+	digits := s
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		digits = digits[1:]
+	}
+
+	var scale int32
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		scale = int32(len(digits) - i - 1)
+		digits = digits[:i] + digits[i+1:]
+	}
+
+	if digits == "" {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+	}
+
+	v, _ := new(big.Int).SetString(digits, 10)
+	if strings.HasPrefix(s, "-") {
+		v.Neg(v)
+	}
+
+	return Decimal{value: v, scale: scale}, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics if s cannot be parsed
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func (d Decimal) int() *big.Int {
+	if d.value == nil {
+		return new(big.Int)
+	}
+
+	return d.value
+}
+
+// rescale returns the unscaled value of d at a scale greater or equal to d.scale
+func (d Decimal) rescale(scale int32) *big.Int {
+	v := d.int()
+	if scale == d.scale {
+		return v
+	}
+
+	return new(big.Int).Mul(v, pow10(scale-d.scale))
+}
+
+func align(a, b Decimal) (*big.Int, *big.Int, int32) {
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+
+	return a.rescale(scale), b.rescale(scale), scale
+}
+
+// Add returns d + o
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return Decimal{value: new(big.Int).Add(a, b), scale: scale}
+}
+
+// Sub returns d - o
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return Decimal{value: new(big.Int).Sub(a, b), scale: scale}
+}
+
+// Mul returns d × o
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{value: new(big.Int).Mul(d.int(), o.int()), scale: d.scale + o.scale}
+}
+
+// Div returns d / o rounded half away from zero to places decimal digits, it panics if o is zero
+func (d Decimal) Div(o Decimal, places int32) Decimal {
+	num := new(big.Int).Set(d.int())
+	den := new(big.Int).Set(o.int())
+
+	// d/o × 10^places = d.value/o.value × 10^(places + o.scale - d.scale)
+	if exp := places + o.scale - d.scale; exp >= 0 {
+		num.Mul(num, pow10(exp))
+	} else {
+		den.Mul(den, pow10(-exp))
+	}
+
+	return Decimal{value: quoRound(num, den), scale: places}
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Abs returns |d|
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Round returns d rounded half away from zero to exactly places decimal digits
+func (d Decimal) Round(places int32) Decimal {
+	if places >= d.scale {
+		return Decimal{value: d.rescale(places), scale: places}
+	}
+
+	return Decimal{value: quoRound(d.int(), pow10(d.scale-places)), scale: places}
+}
+
+// Truncate returns d with exactly places decimal digits, dropping any further digits
+func (d Decimal) Truncate(places int32) Decimal {
+	if places >= d.scale {
+		return Decimal{value: d.rescale(places), scale: places}
+	}
+
+	return Decimal{value: new(big.Int).Quo(d.int(), pow10(d.scale-places)), scale: places}
+}
+
+// Cmp returns -1, 0 or +1 when d is less than, equal to or greater than o
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, _ := align(d, o)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and o are the same number, regardless of scale
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+// LessThan reports whether d < o
+func (d Decimal) LessThan(o Decimal) bool {
+	return d.Cmp(o) < 0
+}
+
+// GreaterThan reports whether d > o
+func (d Decimal) GreaterThan(o Decimal) bool {
+	return d.Cmp(o) > 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero reports whether d is 0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Scale returns the number of digits after the decimal point
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Float64 returns the nearest float64 to d
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns d keeping its scale, e.g. "10.50"
+func (d Decimal) String() string {
+	v := d.int()
+	digits := new(big.Int).Abs(v).String()
+
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+
+		i := len(digits) - int(d.scale)
+		digits = digits[:i] + "." + digits[i:]
+	}
+
+	if v.Sign() < 0 {
+		return "-" + digits
+	}
+
+	return digits
+}
+
+// StringFixed returns d rounded to exactly places decimal digits
+func (d Decimal) StringFixed(places int32) string {
+	return d.Round(places).String()
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON accepts both JSON strings and numbers, null and "" are decoded as 0
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*d = Decimal{}
+		return nil
+	}
+
+	s := string(b)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	if s == "" {
+		*d = Decimal{}
+		return nil
+	}
+
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+
+	*d = v
+	return nil
+}
+
+// MinDecimal returns the smallest of a and b
+func MinDecimal(a, b Decimal) Decimal {
+	if b.LessThan(a) {
+		return b
+	}
+
+	return a
+}
+
+// MaxDecimal returns the greatest of a and b
+func MaxDecimal(a, b Decimal) Decimal {
+	if b.GreaterThan(a) {
+		return b
+	}
+
+	return a
+}
+
+// Shares is an exact quantity of shares or fund units
+type Shares Decimal
+
+// ParseShares parses a share quantity such as "1.500000"
+func ParseShares(s string) (Shares, error) {
+	d, err := ParseDecimal(s)
+	return Shares(d), err
+}
+
+// MustParseShares is like ParseShares but panics if s cannot be parsed
+func MustParseShares(s string) Shares {
+	return Shares(MustParseDecimal(s))
+}
+
+// Decimal returns the quantity as a Decimal
+func (s Shares) Decimal() Decimal {
+	return Decimal(s)
+}
+
+// Add returns s + o
+func (s Shares) Add(o Shares) Shares {
+	return Shares(Decimal(s).Add(Decimal(o)))
+}
+
+// Sub returns s - o
+func (s Shares) Sub(o Shares) Shares {
+	return Shares(Decimal(s).Sub(Decimal(o)))
+}
+
+// Value returns the value of s shares at price per share
+func (s Shares) Value(price Decimal) Decimal {
+	return Decimal(s).Mul(price)
+}
+
+// Round returns s rounded to exactly places decimal digits
+func (s Shares) Round(places int32) Shares {
+	return Shares(Decimal(s).Round(places))
+}
+
+// Cmp returns -1, 0 or +1 when s is less than, equal to or greater than o
+func (s Shares) Cmp(o Shares) int {
+	return Decimal(s).Cmp(Decimal(o))
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of s
+func (s Shares) Sign() int {
+	return Decimal(s).Sign()
+}
+
+// IsZero reports whether s is 0
+func (s Shares) IsZero() bool {
+	return Decimal(s).IsZero()
+}
+
+func (s Shares) String() string {
+	return Decimal(s).String()
+}
+
+func (s Shares) MarshalJSON() ([]byte, error) {
+	return Decimal(s).MarshalJSON()
+}
+
+func (s *Shares) UnmarshalJSON(b []byte) error {
+	return (*Decimal)(s).UnmarshalJSON(b)
+}
+
+// Money is an exact amount in a currency such as "nzd"
+type Money struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// NewMoney returns amount in currency
+func NewMoney(amount Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToLower(currency)}
+}
+
+// Add returns m + o, it fails when the currencies differ
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+
+	return Money{Amount: m.Amount.Add(o.Amount), Currency: m.Currency}, nil
+}
+
+// Sub returns m - o, it fails when the currencies differ
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+
+	return Money{Amount: m.Amount.Sub(o.Amount), Currency: m.Currency}, nil
+}
+
+// Mul returns m × factor in the same currency
+func (m Money) Mul(factor Decimal) Money {
+	return Money{Amount: m.Amount.Mul(factor), Currency: m.Currency}
+}
+
+// Cmp compares m and o, it fails when the currencies differ
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+
+	return m.Amount.Cmp(o.Amount), nil
+}
+
+// IsZero reports whether the amount is 0
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+// String returns the amount rounded to cents followed by the currency, e.g. "10.50 NZD"
+func (m Money) String() string {
+	return m.Amount.StringFixed(2) + " " + strings.ToUpper(m.Currency)
+}
+
+func (m Money) sameCurrency(o Money) error {
+	if m.Currency != o.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+
+	return nil
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(ten, big.NewInt(int64(n)), nil)
+}
+
+// quoRound returns num/den rounded half away from zero
+func quoRound(num, den *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	r2 := new(big.Int).Abs(r)
+	r2.Lsh(r2, 1)
+	if r2.Cmp(new(big.Int).Abs(den)) >= 0 {
+		if (num.Sign() < 0) != (den.Sign() < 0) {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	return q
+}
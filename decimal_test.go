@@ -0,0 +1,87 @@
+package sharesies_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+func Test_ParseDecimal(t *testing.T) {
+	for _, v := range []string{"0", "10.00", "-0.05000000", "0.006616843053100794", "125.87"} {
+		d, err := sharesies.ParseDecimal(v)
+
+		assert.Nil(t, err)
+		assert.Equal(t, v, d.String())
+	}
+
+	for _, v := range []string{"", "-", "1.2.3", "1e5", "abc"} {
+		_, err := sharesies.ParseDecimal(v)
+
+		assert.ErrorIs(t, err, sharesies.ErrInvalidDecimal)
+	}
+}
+
+func Test_Decimal_Arithmetic(t *testing.T) {
+	a := sharesies.MustParseDecimal("0.1")
+	b := sharesies.MustParseDecimal("0.2")
+
+	assert.Equal(t, "0.3", a.Add(b).String())
+	assert.Equal(t, "-0.1", a.Sub(b).String())
+	assert.Equal(t, "0.02", a.Mul(b).String())
+	assert.Equal(t, "0.333333", sharesies.NewDecimal(1, 0).Div(sharesies.NewDecimal(3, 0), 6).String())
+	assert.Equal(t, "-0.67", sharesies.NewDecimal(-2, 0).Div(sharesies.NewDecimal(3, 0), 2).String())
+	assert.True(t, a.Add(b).Equal(sharesies.MustParseDecimal("0.30")))
+	assert.True(t, a.LessThan(b))
+	assert.Equal(t, 0, sharesies.Decimal{}.Sign())
+}
+
+func Test_Decimal_Round(t *testing.T) {
+	assert.Equal(t, "10.00", sharesies.NewDecimal(10, 0).Round(2).String())
+	assert.Equal(t, "1.01", sharesies.MustParseDecimal("1.005").Round(2).String())
+	assert.Equal(t, "-1.01", sharesies.MustParseDecimal("-1.005").Round(2).String())
+	assert.Equal(t, "1.00", sharesies.MustParseDecimal("1.009").Truncate(2).String())
+	assert.Equal(t, "0.001000", sharesies.NewDecimalFromFloat(0.001).StringFixed(6))
+}
+
+func Test_Decimal_JSON(t *testing.T) {
+	var v struct {
+		A sharesies.Decimal `json:"a"`
+		B sharesies.Decimal `json:"b"`
+		C sharesies.Decimal `json:"c"`
+		D sharesies.Shares  `json:"d"`
+	}
+
+	err := json.Unmarshal([]byte(`{"a":"12.50","b":3.25,"c":null,"d":"1.000001"}`), &v)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "12.50", v.A.String())
+	assert.Equal(t, "3.25", v.B.String())
+	assert.True(t, v.C.IsZero())
+	assert.Equal(t, "1.000001", v.D.String())
+
+	b, _ := json.Marshal(&v)
+	assert.Equal(t, `{"a":"12.50","b":"3.25","c":"0","d":"1.000001"}`, string(b))
+}
+
+func Test_Shares(t *testing.T) {
+	s := sharesies.MustParseShares("1.5").Add(sharesies.MustParseShares("0.25"))
+
+	assert.Equal(t, "1.75", s.String())
+	assert.Equal(t, "3.5000", s.Value(sharesies.MustParseDecimal("2.00")).String())
+	assert.Equal(t, 1, s.Cmp(sharesies.MustParseShares("1.7")))
+}
+
+func Test_Money(t *testing.T) {
+	a := sharesies.NewMoney(sharesies.MustParseDecimal("10.10"), "NZD")
+	b := sharesies.NewMoney(sharesies.MustParseDecimal("0.20"), "nzd")
+
+	sum, err := a.Add(b)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.30 NZD", sum.String())
+
+	_, err = a.Add(sharesies.NewMoney(sharesies.NewDecimal(1, 0), "usd"))
+	assert.ErrorIs(t, err, sharesies.ErrCurrencyMismatch)
+}
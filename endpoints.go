@@ -0,0 +1,70 @@
+package sharesies
+
+import "strings"
+
+var defaultEndpoints = NewEndpoints(DefaultAppURL, DefaultDataURL)
+
+// Endpoints holds the full URL of every Sharesies API used by the Client
+type Endpoints struct {
+	IdentityLogin  string
+	IdentityCheck  string
+	IdentityReAuth string
+	Instruments    string
+	CostBuy        string
+	CreateBuy      string
+	CostSell       string
+	CreateSell     string
+	Orders         string
+	Order          string
+	CancelOrder    string
+	Transactions   string
+
+	CreateAutoinvest  string
+	UpdateAutoinvest  string
+	PauseAutoinvest   string
+	ResumeAutoinvest  string
+	DeleteAutoinvest  string
+	PremadeAutoinvest string
+
+	Wallet           string
+	DepositReference string
+	Withdraw         string
+
+	CostExchange   string
+	CreateExchange string
+}
+
+// NewEndpoints builds Endpoints from the app and data API base URLs
+func NewEndpoints(appURL, dataURL string) *Endpoints {
+	appURL = strings.TrimRight(appURL, "/")
+	dataURL = strings.TrimRight(dataURL, "/")
+
+	return &Endpoints{
+		IdentityLogin:  appURL + "/api/identity/login",
+		IdentityCheck:  appURL + "/api/identity/check",
+		IdentityReAuth: appURL + "/api/identity/reauthenticate",
+		Instruments:    dataURL + "/api/v1/instruments",
+		CostBuy:        appURL + "/api/order/cost-buy",
+		CreateBuy:      appURL + "/api/order/create-buy",
+		CostSell:       appURL + "/api/order/cost-sell",
+		CreateSell:     appURL + "/api/order/create-sell",
+		Orders:         appURL + "/api/order/list",
+		Order:          appURL + "/api/order/get",
+		CancelOrder:    appURL + "/api/order/cancel",
+		Transactions:   appURL + "/api/accounting/transaction-history",
+
+		CreateAutoinvest:  appURL + "/api/autoinvest/create",
+		UpdateAutoinvest:  appURL + "/api/autoinvest/update",
+		PauseAutoinvest:   appURL + "/api/autoinvest/pause",
+		ResumeAutoinvest:  appURL + "/api/autoinvest/resume",
+		DeleteAutoinvest:  appURL + "/api/autoinvest/delete",
+		PremadeAutoinvest: appURL + "/api/autoinvest/premade-orders",
+
+		Wallet:           appURL + "/api/wallet/balances",
+		DepositReference: appURL + "/api/wallet/deposit-reference",
+		Withdraw:         appURL + "/api/wallet/withdraw",
+
+		CostExchange:   appURL + "/api/fx/cost",
+		CreateExchange: appURL + "/api/fx/create",
+	}
+}
@@ -0,0 +1,133 @@
+package sharesies
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	ErrorCodeInsufficientFunds  = "insufficient_funds"
+	ErrorCodeInsufficientShares = "insufficient_shares"
+	ErrorCodeMarketClosed       = "market_closed"
+	ErrorCodeInvalidFund        = "invalid_fund"
+	ErrorCodeOrderNotFound      = "order_not_found"
+	ErrorCodeOrderNotPending    = "order_not_pending"
+	ErrorCodeNoAutoinvest       = "autoinvest_not_found"
+	ErrorCodeRateChanged        = "exchange_rate_changed"
+)
+
+// APIError is returned when Sharesies responds with a non-200 status code,
+// it matches ErrHttpRequest when compared with errors.Is
+type APIError struct {
+	StatusCode int
+	Endpoint   string
+	Body       []byte
+	Type       string `json:"type"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	// RetryAfter is the delay requested by the Retry-After header, if any
+	RetryAfter time.Duration `json:"-"`
+}
+
+func newAPIError(endpoint string, res *http.Response, body []byte) *APIError {
+	e := &APIError{}
+
+	// Not every failure carries a JSON body, the status code is enough then
+	_ = json.Unmarshal(body, e)
+
+	e.StatusCode = res.StatusCode
+	e.Endpoint = endpoint
+	e.Body = body
+	e.RetryAfter = parseRetryAfter(res.Header, time.Now())
+
+	return e
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s: %s returned %d", ErrHttpRequest, e.Endpoint, e.StatusCode)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+
+	return msg
+}
+
+// Is reports whether target is ErrHttpRequest
+func (e *APIError) Is(target error) bool {
+	return target == ErrHttpRequest
+}
+
+// Retryable reports whether the same request may succeed if sent again later
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// IsUnauthorized reports whether err is an APIError caused by a missing or expired session
+func IsUnauthorized(err error) bool {
+	var e *APIError
+	return errors.As(err, &e) && e.StatusCode == http.StatusUnauthorized
+}
+
+// IsRateLimited reports whether err is an APIError caused by too many requests
+func IsRateLimited(err error) bool {
+	var e *APIError
+	return errors.As(err, &e) && e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsInsufficientFunds reports whether err is an APIError caused by a wallet balance too low for the order
+func IsInsufficientFunds(err error) bool {
+	return hasErrorCode(err, ErrorCodeInsufficientFunds)
+}
+
+// IsInsufficientShares reports whether err is an APIError caused by selling more shares than held
+func IsInsufficientShares(err error) bool {
+	return hasErrorCode(err, ErrorCodeInsufficientShares)
+}
+
+// IsMarketClosed reports whether err is an APIError caused by the market being closed
+func IsMarketClosed(err error) bool {
+	return hasErrorCode(err, ErrorCodeMarketClosed)
+}
+
+// IsInvalidFund reports whether err is an APIError caused by an unknown fund
+func IsInvalidFund(err error) bool {
+	return hasErrorCode(err, ErrorCodeInvalidFund)
+}
+
+// IsOrderNotFound reports whether err is an APIError caused by an unknown order
+func IsOrderNotFound(err error) bool {
+	return hasErrorCode(err, ErrorCodeOrderNotFound)
+}
+
+// IsOrderNotPending reports whether err is an APIError caused by cancelling an order that is no longer pending
+func IsOrderNotPending(err error) bool {
+	return hasErrorCode(err, ErrorCodeOrderNotPending)
+}
+
+// IsNoAutoinvest reports whether err is an APIError caused by changing an autoinvest order the account does not have
+func IsNoAutoinvest(err error) bool {
+	return hasErrorCode(err, ErrorCodeNoAutoinvest)
+}
+
+// IsRateChanged reports whether err is an APIError caused by exchanging at a rate that has moved since the quote
+func IsRateChanged(err error) bool {
+	return hasErrorCode(err, ErrorCodeRateChanged)
+}
+
+// IsRetryable reports whether err is a transient APIError (rate limited or server failure)
+func IsRetryable(err error) bool {
+	var e *APIError
+	return errors.As(err, &e) && e.Retryable()
+}
+
+func hasErrorCode(err error, code string) bool {
+	var e *APIError
+	return errors.As(err, &e) && e.Code == code
+}
@@ -0,0 +1,81 @@
+package sharesies_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+func Test_APIError(t *testing.T) {
+	mockClient := &MockClient{}
+	authSuccess(mockClient)
+
+	costBuyUrl, _ := url.Parse("https://app.sharesies.nz/api/order/cost-buy")
+	body := `{"type":"error","code":"insufficient_funds","message":"wallet balance is too low"}`
+
+	mockClient.On("Do", http.MethodPost, costBuyUrl, mock.Anything).Return(&http.Response{StatusCode: http.StatusBadRequest, Body: ioutil.NopCloser(strings.NewReader(body))}, nil)
+
+	s := sharesies.Sharesies{
+		HttpClient: mockClient,
+	}
+
+	ctx := context.Background()
+	s.Authenticate(ctx, &sharesies.Credentials{Username: "username", Password: "password"})
+
+	_, err := s.CostBuy(ctx, "b8b7ef58-b270-4762-a256-9d68aebc3e23", sharesies.NewDecimal(10, 0))
+
+	var apiErr *sharesies.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "https://app.sharesies.nz/api/order/cost-buy", apiErr.Endpoint)
+	assert.Equal(t, body, string(apiErr.Body))
+	assert.Equal(t, "insufficient_funds", apiErr.Code)
+	assert.Equal(t, "wallet balance is too low", apiErr.Message)
+	assert.ErrorIs(t, err, sharesies.ErrHttpRequest)
+	assert.True(t, sharesies.IsInsufficientFunds(err))
+	assert.False(t, sharesies.IsRetryable(err))
+}
+
+func Test_APIError_Classification(t *testing.T) {
+	rateLimited := fmt.Errorf("quote: %w", &sharesies.APIError{StatusCode: http.StatusTooManyRequests})
+	unavailable := &sharesies.APIError{StatusCode: http.StatusServiceUnavailable}
+	marketClosed := &sharesies.APIError{StatusCode: http.StatusBadRequest, Code: sharesies.ErrorCodeMarketClosed}
+
+	assert.True(t, sharesies.IsRateLimited(rateLimited))
+	assert.True(t, sharesies.IsRetryable(rateLimited))
+	assert.True(t, sharesies.IsRetryable(unavailable))
+	assert.False(t, sharesies.IsUnauthorized(unavailable))
+	assert.True(t, sharesies.IsMarketClosed(marketClosed))
+	assert.False(t, sharesies.IsRetryable(marketClosed))
+	assert.False(t, sharesies.IsRetryable(errors.New("boom")))
+}
+
+func Test_APIError_RetryAfter(t *testing.T) {
+	mockClient := &MockClient{}
+	authUrl, _ := url.Parse("https://app.sharesies.nz/api/identity/login")
+	header := http.Header{"Retry-After": []string{"3"}}
+
+	mockClient.On("Do", http.MethodPost, authUrl, mock.Anything).Return(&http.Response{StatusCode: http.StatusTooManyRequests, Header: header}, nil)
+
+	s := sharesies.Sharesies{
+		HttpClient: mockClient,
+	}
+
+	_, err := s.Authenticate(context.Background(), &sharesies.Credentials{Username: "username", Password: "password"})
+
+	var apiErr *sharesies.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.True(t, sharesies.IsRateLimited(err))
+	assert.Equal(t, 3*time.Second, apiErr.RetryAfter)
+}
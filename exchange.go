@@ -0,0 +1,99 @@
+package sharesies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidExchange = errors.New("invalid exchange")
+
+// walletCurrencies are the currencies a wallet holds and can exchange between
+var walletCurrencies = []string{"nzd", "usd", "aud"}
+
+// IsExchange reports whether the payment converts from another currency
+func (p *PaymentBreakdown) IsExchange() bool {
+	return p.Type == PaymentTypeExchange
+}
+
+// CostExchange return Cost to convert amount of from currency into to currency
+func (s *Sharesies) CostExchange(ctx context.Context, from, to string, amount Decimal) (*CostExchangeResponse, error) {
+	return s.account().CostExchange(ctx, from, to, amount)
+}
+
+// Exchange converts currency as quoted by CostExchange
+func (s *Sharesies) Exchange(ctx context.Context, costExchange *CostExchangeResponse) (*Transaction, error) {
+	return s.account().Exchange(ctx, costExchange)
+}
+
+// CostExchange return Cost to convert amount of from currency into to currency
+func (a *Account) CostExchange(ctx context.Context, from, to string, amount Decimal) (*CostExchangeResponse, error) {
+	from, to = strings.ToLower(from), strings.ToLower(to)
+	if !isWalletCurrency(from) || !isWalletCurrency(to) {
+		return nil, fmt.Errorf("%w: currencies must be one of %s", ErrInvalidExchange, strings.Join(walletCurrencies, ", "))
+	}
+
+	if from == to {
+		return nil, fmt.Errorf("%w: cannot exchange %s into itself", ErrInvalidExchange, from)
+	}
+
+	if amount.Sign() <= 0 || !amount.Round(2).Equal(amount) {
+		return nil, fmt.Errorf("%w: amount must be positive with at most 2 decimal places", ErrInvalidExchange)
+	}
+
+	actingAsID, err := a.actingAsID()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &CostExchangeResponse{ActingAsID: actingAsID, IdempotencyKey: uuid.NewString()}
+	cr := &CostExchangeRequest{
+		ActingAsID:     actingAsID,
+		SourceCurrency: from,
+		TargetCurrency: to,
+		SourceAmount:   amount,
+	}
+
+	err = a.s.authRequest(ctx, http.MethodPost, a.s.endpoints().CostExchange, false, cr, r)
+	return r, err
+}
+
+// Exchange converts currency as quoted by CostExchange, it fails with an
+// APIError matched by IsRateChanged if the rate has moved since the quote
+func (a *Account) Exchange(ctx context.Context, costExchange *CostExchangeResponse) (*Transaction, error) {
+	actingAsID, err := a.quotedAs(costExchange.ActingAsID)
+	if err != nil {
+		return nil, err
+	}
+
+	if costExchange.IdempotencyKey == "" {
+		costExchange.IdempotencyKey = uuid.NewString()
+	}
+
+	r := &ExchangeResponse{}
+	er := &CreateExchangeRequest{
+		ActingAsID:           actingAsID,
+		SourceCurrency:       costExchange.SourceCurrency,
+		TargetCurrency:       costExchange.TargetCurrency,
+		SourceAmount:         costExchange.SourceAmount,
+		ExpectedTargetAmount: costExchange.TargetAmount,
+		IdempotencyKey:       costExchange.IdempotencyKey,
+	}
+
+	err = a.s.authRequest(ctx, http.MethodPost, a.s.endpoints().CreateExchange, false, er, r)
+	return r.Transaction, err
+}
+
+func isWalletCurrency(currency string) bool {
+	for _, c := range walletCurrencies {
+		if c == currency {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+package sharesies_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/sharesiestest"
+)
+
+func Test_CostExchange_Invalid(t *testing.T) {
+	s, _ := sharesies.New(nil)
+
+	cases := []struct{ from, to, amount string }{
+		{"nzd", "nzd", "10"},
+		{"nzd", "gbp", "10"},
+		{"nzd", "usd", "0"},
+		{"nzd", "usd", "10.001"},
+	}
+
+	for _, c := range cases {
+		_, err := s.CostExchange(context.Background(), c.from, c.to, sharesies.MustParseDecimal(c.amount))
+		assert.True(t, errors.Is(err, sharesies.ErrInvalidExchange), c)
+	}
+}
+
+func Test_Exchange(t *testing.T) {
+	srv, acc, s := newFakeServer(t)
+	ctx := context.Background()
+
+	_, err := s.CostExchange(ctx, "nzd", "usd", sharesies.NewDecimal(101, 0))
+	assert.True(t, sharesies.IsInsufficientFunds(err))
+
+	cost, err := s.CostExchange(ctx, "NZD", "USD", sharesies.NewDecimal(50, 0))
+	assert.Nil(t, err)
+	assert.True(t, cost.Rate.Equal(sharesiestest.DefaultNZDUSDRate))
+	assert.Equal(t, "0.15", cost.Fee.String())
+	assert.Equal(t, "29.85", cost.TargetAmount.String())
+
+	srv.SetExchangeRate("usd", "nzd", sharesies.MustParseDecimal("1.6"))
+	_, err = s.Exchange(ctx, cost)
+	assert.True(t, sharesies.IsRateChanged(err))
+
+	cost, err = s.CostExchange(ctx, "nzd", "usd", sharesies.NewDecimal(50, 0))
+	assert.Nil(t, err)
+	assert.True(t, cost.Rate.Equal(sharesies.MustParseDecimal("0.625")))
+
+	tx, err := s.Exchange(ctx, cost)
+	assert.Nil(t, err)
+	assert.Equal(t, sharesies.TransactionTypeExchange, tx.Type)
+	assert.Equal(t, "usd", tx.ExchangeCurrency)
+	assert.True(t, tx.ExchangeAmount.Equal(cost.TargetAmount))
+
+	again, err := s.Exchange(ctx, cost)
+	assert.Nil(t, err)
+	assert.Equal(t, tx.ID, again.ID)
+
+	assert.True(t, acc.Balance("nzd").Equal(sharesies.NewDecimal(50, 0)))
+	assert.True(t, acc.Balance("usd").Equal(cost.TargetAmount))
+}
@@ -0,0 +1,141 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// Column is a field of a CSV export
+type Column string
+
+const (
+	ColumnID Column = "id"
+	// ColumnDate is the New Zealand calendar day of the transaction
+	ColumnDate             Column = "date"
+	ColumnTimestamp        Column = "timestamp"
+	ColumnType             Column = "type"
+	ColumnDescription      Column = "description"
+	ColumnFundID           Column = "fund_id"
+	ColumnSymbol           Column = "symbol"
+	ColumnName             Column = "name"
+	ColumnOrderID          Column = "order_id"
+	ColumnShares           Column = "shares"
+	ColumnPrice            Column = "price"
+	ColumnAmount           Column = "amount"
+	ColumnFee              Column = "fee"
+	ColumnTaxWithheld      Column = "tax_withheld"
+	ColumnCurrency         Column = "currency"
+	ColumnBalance          Column = "balance"
+	ColumnExchangeCurrency Column = "exchange_currency"
+	ColumnExchangeAmount   Column = "exchange_amount"
+	ColumnExchangeRate     Column = "exchange_rate"
+)
+
+var ErrUnknownColumn = errors.New("unknown column")
+
+// DefaultColumns are the columns written by WriteCSV when none are given
+var DefaultColumns = []Column{
+	ColumnDate,
+	ColumnType,
+	ColumnSymbol,
+	ColumnShares,
+	ColumnPrice,
+	ColumnAmount,
+	ColumnFee,
+	ColumnCurrency,
+	ColumnDescription,
+}
+
+// WriteCSV writes a header row named after columns, then one row per
+// transaction of h. DefaultColumns are used when columns is empty.
+func WriteCSV(w io.Writer, h *History, columns []Column) error {
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		if _, err := c.value(h, &sharesies.Transaction{}); err != nil {
+			return err
+		}
+
+		header[i] = string(c)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, t := range h.Transactions {
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i], _ = c.value(h, t)
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func (c Column) value(h *History, t *sharesies.Transaction) (string, error) {
+	switch c {
+	case ColumnID:
+		return t.ID, nil
+	case ColumnDate:
+		return sharesies.NewDate(t.Timestamp.In(sharesies.NZ)).String(), nil
+	case ColumnTimestamp:
+		return t.Timestamp.Format(time.RFC3339), nil
+	case ColumnType:
+		return t.Type, nil
+	case ColumnDescription:
+		return t.Description, nil
+	case ColumnFundID:
+		return t.FundID, nil
+	case ColumnSymbol:
+		return optional(t.FundID != "", func() string { return h.Symbol(t.FundID) }), nil
+	case ColumnName:
+		return optional(t.FundID != "", func() string { return h.Name(t.FundID) }), nil
+	case ColumnOrderID:
+		return t.OrderID, nil
+	case ColumnShares:
+		return optional(t.Shares != nil, func() string { return t.Shares.String() }), nil
+	case ColumnPrice:
+		return optional(t.Price != nil, func() string { return t.Price.String() }), nil
+	case ColumnAmount:
+		return t.Amount.String(), nil
+	case ColumnFee:
+		return t.Fee.String(), nil
+	case ColumnTaxWithheld:
+		return t.TaxWithheld.String(), nil
+	case ColumnCurrency:
+		return t.Currency, nil
+	case ColumnBalance:
+		return t.Balance.String(), nil
+	case ColumnExchangeCurrency:
+		return t.ExchangeCurrency, nil
+	case ColumnExchangeAmount:
+		return optional(t.ExchangeAmount != nil, func() string { return t.ExchangeAmount.String() }), nil
+	case ColumnExchangeRate:
+		return optional(t.ExchangeRate != nil, func() string { return t.ExchangeRate.String() }), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownColumn, c)
+	}
+}
+
+func optional(ok bool, value func() string) string {
+	if !ok {
+		return ""
+	}
+
+	return value()
+}
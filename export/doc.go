@@ -0,0 +1,14 @@
+// Package export writes Sharesies transaction history in formats accounting
+// software can import: CSV, OFX 2.x investment statements, QIF, and
+// Beancount or Ledger journals for plain text accounting.
+//
+// Fetch retrieves the history and resolves fund IDs to their symbols, the
+// writers then render it:
+//
+//	h, err := export.Fetch(ctx, s, &sharesies.TransactionFilter{From: from, To: to})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	err = export.WriteOFX(os.Stdout, h, &export.OFXOptions{AccountID: "sharesies"})
+package export
@@ -0,0 +1,192 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/export"
+	"github.com/deividfortuna/sharesies/sharesiestest"
+)
+
+const fundID = "b8b7ef58-b270-4762-a256-9d68aebc3e23"
+
+func decimal(s string) *sharesies.Decimal {
+	d := sharesies.MustParseDecimal(s)
+	return &d
+}
+
+func shares(s string) *sharesies.Shares {
+	sh := sharesies.MustParseShares(s)
+	return &sh
+}
+
+func day(d int) time.Time {
+	return time.Date(2021, time.June, d, 10, 0, 0, 0, time.UTC)
+}
+
+func history() *export.History {
+	return &export.History{
+		Instruments: map[string]*sharesies.Company{
+			fundID: {ID: fundID, Symbol: "AIR", Name: "Air New Zealand"},
+		},
+		Transactions: []*sharesies.Transaction{
+			{ID: "t1", Type: sharesies.TransactionTypeDeposit, Timestamp: day(1), Description: "Deposit", Currency: "nzd", Amount: sharesies.MustParseDecimal("100.00"), Balance: sharesies.MustParseDecimal("100.00")},
+			{ID: "t2", Type: sharesies.TransactionTypeBuy, Timestamp: day(2), Currency: "nzd", Amount: sharesies.MustParseDecimal("-50.00"), Balance: sharesies.MustParseDecimal("50.00"), FundID: fundID, Shares: shares("24.875"), Price: decimal("2.00"), Fee: sharesies.MustParseDecimal("0.25")},
+			{ID: "t3", Type: sharesies.TransactionTypeDividend, Timestamp: day(3), Currency: "nzd", Amount: sharesies.MustParseDecimal("1.20"), Balance: sharesies.MustParseDecimal("51.20"), FundID: fundID, TaxWithheld: sharesies.MustParseDecimal("0.30")},
+			{ID: "t4", Type: sharesies.TransactionTypeSell, Timestamp: day(4), Currency: "nzd", Amount: sharesies.MustParseDecimal("19.90"), Balance: sharesies.MustParseDecimal("71.10"), FundID: fundID, Shares: shares("10"), Price: decimal("2.00"), Fee: sharesies.MustParseDecimal("0.10")},
+			{ID: "t5", Type: sharesies.TransactionTypeExchange, Timestamp: day(5), Currency: "nzd", Amount: sharesies.MustParseDecimal("-20.00"), Balance: sharesies.MustParseDecimal("51.10"), ExchangeCurrency: "usd", ExchangeAmount: decimal("14.00"), ExchangeRate: decimal("0.70")},
+		},
+	}
+}
+
+func Test_Fetch(t *testing.T) {
+	srv := sharesiestest.NewServer()
+	defer srv.Close()
+
+	srv.AddInstrument(&sharesies.Company{ID: fundID, Symbol: "AIR", Name: "Air New Zealand", Marketprice: sharesies.MustParseDecimal("2.00"), Exchangecountry: "nzl"})
+	acc := srv.AddUser("username", "password")
+	acc.Deposit("nzd", sharesies.NewDecimal(100, 0))
+
+	s, _ := sharesies.NewWithOptions(srv.Options())
+	ctx := context.Background()
+	_, err := s.Authenticate(ctx, &sharesies.Credentials{Username: "username", Password: "password"})
+	assert.Nil(t, err)
+
+	costBuy, _ := s.CostBuy(ctx, fundID, sharesies.NewDecimal(50, 0))
+	_, err = s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+
+	h, err := export.Fetch(ctx, s, nil)
+
+	assert.Nil(t, err)
+	assert.Len(t, h.Transactions, 2)
+	assert.Equal(t, sharesies.TransactionTypeDeposit, h.Transactions[0].Type)
+	assert.Equal(t, sharesies.TransactionTypeBuy, h.Transactions[1].Type)
+	assert.Equal(t, "AIR", h.Symbol(fundID))
+	assert.Equal(t, "unknown", h.Symbol("unknown"))
+}
+
+func Test_WriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	err := export.WriteCSV(&b, history(), nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, `date,type,symbol,shares,price,amount,fee,currency,description
+2021-06-01,deposit,,,,100.00,0,nzd,Deposit
+2021-06-02,buy,AIR,24.875,2.00,-50.00,0.25,nzd,
+2021-06-03,dividend,AIR,,,1.20,0,nzd,
+2021-06-04,sell,AIR,10,2.00,19.90,0.10,nzd,
+2021-06-05,exchange,,,,-20.00,0,nzd,
+`, b.String())
+}
+
+func Test_WriteCSV_Columns(t *testing.T) {
+	var b bytes.Buffer
+	err := export.WriteCSV(&b, history(), []export.Column{export.ColumnID, export.ColumnName, export.ColumnExchangeAmount})
+
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(b.String(), "id,name,exchange_amount\nt1,,\nt2,Air New Zealand,\n"))
+	assert.True(t, strings.HasSuffix(b.String(), "t5,,14.00\n"))
+
+	err = export.WriteCSV(io.Discard, history(), []export.Column{"isin"})
+	assert.ErrorIs(t, err, export.ErrUnknownColumn)
+}
+
+func Test_WriteCSV_NZDate(t *testing.T) {
+	h := history()
+	// 09:00 NZDT on 1 April, still 31 March in UTC
+	h.Transactions = h.Transactions[:1]
+	h.Transactions[0].Timestamp = time.Date(2021, time.March, 31, 20, 0, 0, 0, time.UTC)
+
+	var b bytes.Buffer
+	assert.Nil(t, export.WriteCSV(&b, h, []export.Column{export.ColumnDate}))
+	assert.Equal(t, "date\n2021-04-01\n", b.String())
+
+	b.Reset()
+	assert.Nil(t, export.WriteQIF(&b, h, nil))
+	assert.Contains(t, b.String(), "D04/01/2021\n")
+}
+
+func Test_WriteOFX(t *testing.T) {
+	var b bytes.Buffer
+	err := export.WriteOFX(&b, history(), &export.OFXOptions{AccountID: "acc", Now: day(30)})
+	assert.Nil(t, err)
+
+	var doc struct {
+		Statements []struct {
+			CurDef    string   `xml:"INVSTMTRS>CURDEF"`
+			Buys      []string `xml:"INVSTMTRS>INVTRANLIST>BUYSTOCK>INVBUY>TOTAL"`
+			Sells     []string `xml:"INVSTMTRS>INVTRANLIST>SELLSTOCK>INVSELL>UNITS"`
+			Income    []string `xml:"INVSTMTRS>INVTRANLIST>INCOME>TOTAL"`
+			Withheld  []string `xml:"INVSTMTRS>INVTRANLIST>INCOME>WITHHOLDING"`
+			Bank      []string `xml:"INVSTMTRS>INVTRANLIST>INVBANKTRAN>STMTTRN>TRNAMT"`
+			AvailCash string   `xml:"INVSTMTRS>INVBAL>AVAILCASH"`
+		} `xml:"INVSTMTMSGSRSV1>INVSTMTTRNRS"`
+		Tickers []string `xml:"SECLISTMSGSRSV1>SECLIST>STOCKINFO>SECINFO>TICKER"`
+	}
+
+	body := b.String()[strings.Index(b.String(), "<OFX>"):]
+	assert.Nil(t, xml.Unmarshal([]byte(body), &doc))
+
+	assert.Len(t, doc.Statements, 2)
+	nzd, usd := doc.Statements[0], doc.Statements[1]
+
+	assert.Equal(t, "NZD", nzd.CurDef)
+	assert.Equal(t, []string{"-50.00"}, nzd.Buys)
+	assert.Equal(t, []string{"-10"}, nzd.Sells)
+	assert.Equal(t, []string{"1.50"}, nzd.Income)
+	assert.Equal(t, []string{"0.30"}, nzd.Withheld)
+	assert.Equal(t, []string{"100.00", "-20.00"}, nzd.Bank)
+	assert.Equal(t, "51.10", nzd.AvailCash)
+
+	assert.Equal(t, "USD", usd.CurDef)
+	assert.Equal(t, []string{"14.00"}, usd.Bank)
+	assert.Equal(t, []string{"AIR"}, doc.Tickers)
+}
+
+func Test_WriteQIF(t *testing.T) {
+	var b bytes.Buffer
+	err := export.WriteQIF(&b, history(), &export.QIFOptions{Currency: "NZD"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `!Type:Invst
+D06/01/2021
+NXIn
+T100.00
+MDeposit
+^
+D06/02/2021
+NBuy
+YAIR
+I2.00
+Q24.875
+T50.00
+O0.25
+^
+D06/03/2021
+NDiv
+YAIR
+T1.20
+^
+D06/04/2021
+NSell
+YAIR
+I2.00
+Q10
+T19.90
+O0.10
+^
+D06/05/2021
+NXOut
+T20.00
+^
+`, b.String())
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"context"
+	"sort"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// instrumentsPerPage is how many instruments Fetch resolves per request
+const instrumentsPerPage = 100
+
+// Client is the part of sharesies.Sharesies used to retrieve the history
+type Client interface {
+	Transactions(ctx context.Context, filter *sharesies.TransactionFilter) ([]*sharesies.Transaction, error)
+	Instruments(ctx context.Context, request *sharesies.InstrumentsRequest) (*sharesies.InstrumentResponse, error)
+}
+
+// History is a transaction history, oldest first, with the instruments it refers to
+type History struct {
+	Transactions []*sharesies.Transaction
+	// Instruments maps fund IDs to their Company/Fund
+	Instruments map[string]*sharesies.Company
+}
+
+// Fetch retrieves the transactions matching filter and the instruments they refer to
+func Fetch(ctx context.Context, c Client, filter *sharesies.TransactionFilter) (*History, error) {
+	transactions, err := c.Transactions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Timestamp.Before(transactions[j].Timestamp)
+	})
+
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, t := range transactions {
+		if t.FundID != "" && !seen[t.FundID] {
+			seen[t.FundID] = true
+			ids = append(ids, t.FundID)
+		}
+	}
+
+	h := &History{Transactions: transactions, Instruments: map[string]*sharesies.Company{}}
+	for start := 0; start < len(ids); start += instrumentsPerPage {
+		end := start + instrumentsPerPage
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		r, err := c.Instruments(ctx, &sharesies.InstrumentsRequest{
+			Page:        1,
+			Perpage:     end - start,
+			Instruments: ids[start:end],
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, i := range r.Instruments {
+			h.Instruments[i.ID] = i
+		}
+	}
+
+	return h, nil
+}
+
+// Symbol returns the ticker of fundID, or fundID itself when it is unknown
+func (h *History) Symbol(fundID string) string {
+	if c, ok := h.Instruments[fundID]; ok && c.Symbol != "" {
+		return c.Symbol
+	}
+
+	return fundID
+}
+
+// Name returns the name of fundID, or its Symbol when it is unknown
+func (h *History) Name(fundID string) string {
+	if c, ok := h.Instruments[fundID]; ok && c.Name != "" {
+		return c.Name
+	}
+
+	return h.Symbol(fundID)
+}
+
+// leg is the wallet movement of a transaction in a single currency, an
+// exchange has one leg in each currency
+type leg struct {
+	*sharesies.Transaction
+	currency string
+	amount   sharesies.Decimal
+}
+
+// legs splits the history into wallet movements per currency
+func (h *History) legs() []*leg {
+	legs := make([]*leg, 0, len(h.Transactions))
+	for _, t := range h.Transactions {
+		legs = append(legs, &leg{Transaction: t, currency: t.Currency, amount: t.Amount})
+
+		if t.Type == sharesies.TransactionTypeExchange && t.ExchangeAmount != nil {
+			legs = append(legs, &leg{Transaction: t, currency: t.ExchangeCurrency, amount: *t.ExchangeAmount})
+		}
+	}
+
+	return legs
+}
@@ -0,0 +1,417 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// DefaultAccounts are the account templates used when JournalOptions.Accounts is nil
+var DefaultAccounts = Accounts{
+	Cash:      "Assets:Sharesies:Cash:{{.Currency}}",
+	Holdings:  "Assets:Sharesies:{{.Exchange}}:{{.Symbol}}",
+	Fees:      "Expenses:Sharesies:Fees",
+	Dividends: "Income:Sharesies:Dividends:{{.Symbol}}",
+	Tax:       "Expenses:Taxes:Withholding",
+	Gains:     "Income:Sharesies:Gains",
+	Transfers: "Assets:Bank:{{.Currency}}",
+}
+
+// Accounts names the journal accounts postings are written to. Each name is
+// a text/template executed with the AccountData of the posting, so accounts
+// can be split by exchange, currency or symbol.
+type Accounts struct {
+	// Cash is the wallet
+	Cash string
+	// Holdings holds the shares of an instrument
+	Holdings  string
+	Fees      string
+	Dividends string
+	// Tax is the tax withheld from dividends
+	Tax string
+	// Gains books the profit or loss of sells
+	Gains string
+	// Transfers is where deposits come from and withdrawals go to
+	Transfers string
+}
+
+// AccountData is passed to the Accounts templates, each value is a valid account name component
+type AccountData struct {
+	// Exchange the instrument is listed on, such as "NZX", empty for wallet movements
+	Exchange string
+	// Currency is the upper case currency of the posting, such as "NZD"
+	Currency string
+	// Symbol is the commodity of the instrument, such as "AIR", empty for wallet movements
+	Symbol string
+}
+
+// JournalOptions configures WriteBeancount and WriteLedger
+type JournalOptions struct {
+	Accounts *Accounts
+	// Now dates the price directives of instruments without a market check time, time.Now when zero
+	Now time.Time
+}
+
+// commodityMaxLen is the longest commodity Beancount accepts
+const commodityMaxLen = 24
+
+var (
+	componentInvalid = regexp.MustCompile(`[^A-Za-z0-9-]+`)
+	commodityInvalid = regexp.MustCompile(`[^A-Z0-9'._-]+`)
+	ledgerBare       = regexp.MustCompile(`^[A-Za-z]+$`)
+)
+
+type journalPosting struct {
+	account string
+	amount  string
+}
+
+type journalEntry struct {
+	date      sharesies.Date
+	id        string
+	narration string
+	postings  []journalPosting
+}
+
+// journal renders a History for one of the plain text accounting dialects
+type journal struct {
+	h         *History
+	beancount bool
+	templates map[string]*template.Template
+
+	entries     []*journalEntry
+	opened      map[string]sharesies.Date
+	commodities map[string]sharesies.Date
+}
+
+// WriteBeancount writes h as Beancount directives: commodity and open
+// declarations, one transaction per wallet movement with the cost basis of
+// bought shares, and a price directive per instrument from its market price
+func WriteBeancount(w io.Writer, h *History, opts *JournalOptions) error {
+	return writeJournal(w, h, opts, true)
+}
+
+// WriteLedger writes h as a Ledger/hledger journal: commodity and account
+// declarations, one transaction per wallet movement with the cost basis of
+// bought shares, and a P directive per instrument from its market price
+func WriteLedger(w io.Writer, h *History, opts *JournalOptions) error {
+	return writeJournal(w, h, opts, false)
+}
+
+func writeJournal(w io.Writer, h *History, opts *JournalOptions, beancount bool) error {
+	if opts == nil {
+		opts = &JournalOptions{}
+	}
+
+	accounts := opts.Accounts
+	if accounts == nil {
+		accounts = &DefaultAccounts
+	}
+
+	j := &journal{
+		h:           h,
+		beancount:   beancount,
+		templates:   map[string]*template.Template{},
+		opened:      map[string]sharesies.Date{},
+		commodities: map[string]sharesies.Date{},
+	}
+
+	for name, text := range map[string]string{
+		"cash":      accounts.Cash,
+		"holdings":  accounts.Holdings,
+		"fees":      accounts.Fees,
+		"dividends": accounts.Dividends,
+		"tax":       accounts.Tax,
+		"gains":     accounts.Gains,
+		"transfers": accounts.Transfers,
+	} {
+		t, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return err
+		}
+
+		j.templates[name] = t
+	}
+
+	for _, t := range h.Transactions {
+		if err := j.add(t); err != nil {
+			return err
+		}
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	bw := bufio.NewWriter(w)
+	j.write(bw, now)
+	return bw.Flush()
+}
+
+func (j *journal) add(t *sharesies.Transaction) error {
+	e := &journalEntry{date: sharesies.NewDate(t.Timestamp.In(sharesies.NZ)), id: t.ID, narration: t.Description}
+	data := j.data(t.FundID, t.Currency)
+	symbol := commodity(j.h.Symbol(t.FundID))
+	cash := j.amount(t.Amount, data.Currency)
+
+	var err error
+	post := func(template string, d AccountData, amount string) {
+		if err != nil {
+			return
+		}
+
+		var account string
+		if account, err = j.account(template, d, e.date); err == nil {
+			e.postings = append(e.postings, journalPosting{account: account, amount: amount})
+		}
+	}
+
+	switch {
+	case t.Type == sharesies.TransactionTypeBuy && t.Shares != nil && t.Price != nil:
+		j.commodity(symbol, e.date)
+		e.narration = j.narration(e.narration, "Buy "+symbol)
+		post("holdings", data, fmt.Sprintf("%s %s {%s}", t.Shares, j.commodityName(symbol), j.amount(*t.Price, data.Currency)))
+		if !t.Fee.IsZero() {
+			post("fees", data, j.amount(t.Fee, data.Currency))
+		}
+		post("cash", data, cash)
+	case t.Type == sharesies.TransactionTypeSell && t.Shares != nil && t.Price != nil:
+		j.commodity(symbol, e.date)
+		e.narration = j.narration(e.narration, "Sell "+symbol)
+		lot := ""
+		if j.beancount {
+			lot = " {}"
+		}
+		post("holdings", data, fmt.Sprintf("%s %s%s @ %s", t.Shares.Decimal().Neg(), j.commodityName(symbol), lot, j.amount(*t.Price, data.Currency)))
+		if !t.Fee.IsZero() {
+			post("fees", data, j.amount(t.Fee, data.Currency))
+		}
+		post("cash", data, cash)
+		post("gains", data, "")
+	case t.Type == sharesies.TransactionTypeDividend:
+		e.narration = j.narration(e.narration, "Dividend")
+		if t.FundID != "" {
+			e.narration = j.narration(t.Description, "Dividend "+symbol)
+		}
+		post("cash", data, cash)
+		if !t.TaxWithheld.IsZero() {
+			post("tax", data, j.amount(t.TaxWithheld, data.Currency))
+		}
+		post("dividends", data, j.amount(t.Amount.Add(t.TaxWithheld).Neg(), data.Currency))
+	case t.Type == sharesies.TransactionTypeExchange && t.ExchangeAmount != nil:
+		to := j.data("", t.ExchangeCurrency)
+		e.narration = j.narration(e.narration, "Exchange "+data.Currency+" to "+to.Currency)
+		post("cash", data, fmt.Sprintf("%s @@ %s", cash, j.amount(*t.ExchangeAmount, to.Currency)))
+		post("cash", to, j.amount(*t.ExchangeAmount, to.Currency))
+	case t.Type == sharesies.TransactionTypeFee:
+		e.narration = j.narration(e.narration, "Fee")
+		post("cash", data, cash)
+		post("fees", data, j.amount(t.Amount.Neg(), data.Currency))
+	default:
+		e.narration = j.narration(e.narration, component(t.Type))
+		post("cash", data, cash)
+		post("transfers", data, j.amount(t.Amount.Neg(), data.Currency))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	j.entries = append(j.entries, e)
+	return nil
+}
+
+// data returns the template data of a posting in currency for fundID, which may be empty
+func (j *journal) data(fundID, currency string) AccountData {
+	d := AccountData{Currency: strings.ToUpper(currency)}
+	if fundID == "" {
+		return d
+	}
+
+	d.Exchange = "Other"
+	if c, ok := j.h.Instruments[fundID]; ok && c.Exchange != "" {
+		d.Exchange = component(c.Exchange)
+	}
+
+	d.Symbol = component(j.h.Symbol(fundID))
+	return d
+}
+
+func (j *journal) account(name string, d AccountData, date sharesies.Date) (string, error) {
+	var b bytes.Buffer
+	if err := j.templates[name].Execute(&b, d); err != nil {
+		return "", err
+	}
+
+	account := b.String()
+	if opened, ok := j.opened[account]; !ok || date.Before(opened) {
+		j.opened[account] = date
+	}
+
+	return account, nil
+}
+
+func (j *journal) commodity(symbol string, date sharesies.Date) {
+	if first, ok := j.commodities[symbol]; !ok || date.Before(first) {
+		j.commodities[symbol] = date
+	}
+}
+
+func (j *journal) narration(description, fallback string) string {
+	if description != "" {
+		return description
+	}
+
+	return fallback
+}
+
+func (j *journal) amount(d sharesies.Decimal, currency string) string {
+	return d.String() + " " + currency
+}
+
+// commodityName quotes symbols Ledger cannot parse bare
+func (j *journal) commodityName(symbol string) string {
+	if j.beancount || ledgerBare.MatchString(symbol) {
+		return symbol
+	}
+
+	return strconv.Quote(symbol)
+}
+
+func (j *journal) date(d sharesies.Date) string {
+	if j.beancount {
+		return d.String()
+	}
+
+	return strings.ReplaceAll(d.String(), "-", "/")
+}
+
+func (j *journal) write(w io.Writer, now time.Time) {
+	symbols := make([]string, 0, len(j.commodities))
+	for s := range j.commodities {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+
+	names := map[string]string{}
+	prices := map[string]*sharesies.Company{}
+	for id, c := range j.h.Instruments {
+		symbol := commodity(j.h.Symbol(id))
+		names[symbol] = c.Name
+		if !c.Marketprice.IsZero() {
+			prices[symbol] = c
+		}
+	}
+
+	for _, s := range symbols {
+		if j.beancount {
+			fmt.Fprintf(w, "%s commodity %s\n", j.commodities[s], s)
+			if names[s] != "" {
+				fmt.Fprintf(w, "  name: %s\n", strconv.Quote(names[s]))
+			}
+		} else {
+			fmt.Fprintf(w, "commodity %s\n", j.commodityName(s))
+			if names[s] != "" {
+				fmt.Fprintf(w, "    note %s\n", names[s])
+			}
+		}
+	}
+
+	if len(symbols) > 0 {
+		fmt.Fprintln(w)
+	}
+
+	accounts := make([]string, 0, len(j.opened))
+	for a := range j.opened {
+		accounts = append(accounts, a)
+	}
+	sort.Strings(accounts)
+
+	for _, a := range accounts {
+		if j.beancount {
+			fmt.Fprintf(w, "%s open %s\n", j.opened[a], a)
+		} else {
+			fmt.Fprintf(w, "account %s\n", a)
+		}
+	}
+
+	for _, e := range j.entries {
+		fmt.Fprintln(w)
+		if j.beancount {
+			fmt.Fprintf(w, "%s * %s\n", j.date(e.date), strconv.Quote(e.narration))
+			fmt.Fprintf(w, "  id: %s\n", strconv.Quote(e.id))
+		} else {
+			fmt.Fprintf(w, "%s * %s\n", j.date(e.date), e.narration)
+			fmt.Fprintf(w, "  ; id: %s\n", e.id)
+		}
+
+		for _, p := range e.postings {
+			if p.amount == "" {
+				fmt.Fprintf(w, "  %s\n", p.account)
+			} else {
+				fmt.Fprintf(w, "  %-44s %s\n", p.account, p.amount)
+			}
+		}
+	}
+
+	newline := len(j.entries) > 0
+	for _, s := range symbols {
+		c, ok := prices[s]
+		if !ok {
+			continue
+		}
+
+		if newline {
+			fmt.Fprintln(w)
+			newline = false
+		}
+
+		at := c.Marketlastcheck
+		if at.IsZero() {
+			at = now
+		}
+		on := sharesies.NewDate(at.In(sharesies.NZ))
+
+		price := j.amount(c.Marketprice, strings.ToUpper(c.Currency()))
+		if j.beancount {
+			fmt.Fprintf(w, "%s price %s %s\n", j.date(on), s, price)
+		} else {
+			fmt.Fprintf(w, "P %s %s %s\n", j.date(on), j.commodityName(s), price)
+		}
+	}
+}
+
+// component makes s a valid account name component
+func component(s string) string {
+	s = strings.Trim(componentInvalid.ReplaceAllString(s, "-"), "-")
+	if s == "" {
+		return "Other"
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// commodity makes s a valid Beancount commodity, 2 to 24 characters starting
+// with a letter, so single letter tickers such as T are prefixed too
+func commodity(s string) string {
+	s = strings.Trim(commodityInvalid.ReplaceAllString(strings.ToUpper(s), "-"), "-'._")
+	for len(s) < 2 || s[0] < 'A' || s[0] > 'Z' {
+		s = "X" + s
+	}
+
+	if len(s) > commodityMaxLen {
+		s = strings.TrimRight(s[:commodityMaxLen], "-'._")
+	}
+
+	return s
+}
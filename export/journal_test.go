@@ -0,0 +1,130 @@
+package export_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/export"
+)
+
+func listedHistory() *export.History {
+	h := history()
+	h.Instruments[fundID].Exchange = "NZX"
+	h.Instruments[fundID].Exchangecountry = "nzl"
+	h.Instruments[fundID].Marketprice = sharesies.MustParseDecimal("2.10")
+	h.Instruments[fundID].Marketlastcheck = day(30)
+
+	return h
+}
+
+func Test_WriteBeancount(t *testing.T) {
+	var b bytes.Buffer
+	err := export.WriteBeancount(&b, listedHistory(), nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, `2021-06-02 commodity AIR
+  name: "Air New Zealand"
+
+2021-06-01 open Assets:Bank:NZD
+2021-06-01 open Assets:Sharesies:Cash:NZD
+2021-06-05 open Assets:Sharesies:Cash:USD
+2021-06-02 open Assets:Sharesies:NZX:AIR
+2021-06-02 open Expenses:Sharesies:Fees
+2021-06-03 open Expenses:Taxes:Withholding
+2021-06-03 open Income:Sharesies:Dividends:AIR
+2021-06-04 open Income:Sharesies:Gains
+
+2021-06-01 * "Deposit"
+  id: "t1"
+  Assets:Sharesies:Cash:NZD                    100.00 NZD
+  Assets:Bank:NZD                              -100.00 NZD
+
+2021-06-02 * "Buy AIR"
+  id: "t2"
+  Assets:Sharesies:NZX:AIR                     24.875 AIR {2.00 NZD}
+  Expenses:Sharesies:Fees                      0.25 NZD
+  Assets:Sharesies:Cash:NZD                    -50.00 NZD
+
+2021-06-03 * "Dividend AIR"
+  id: "t3"
+  Assets:Sharesies:Cash:NZD                    1.20 NZD
+  Expenses:Taxes:Withholding                   0.30 NZD
+  Income:Sharesies:Dividends:AIR               -1.50 NZD
+
+2021-06-04 * "Sell AIR"
+  id: "t4"
+  Assets:Sharesies:NZX:AIR                     -10 AIR {} @ 2.00 NZD
+  Expenses:Sharesies:Fees                      0.10 NZD
+  Assets:Sharesies:Cash:NZD                    19.90 NZD
+  Income:Sharesies:Gains
+
+2021-06-05 * "Exchange NZD to USD"
+  id: "t5"
+  Assets:Sharesies:Cash:NZD                    -20.00 NZD @@ 14.00 USD
+  Assets:Sharesies:Cash:USD                    14.00 USD
+
+2021-06-30 price AIR 2.10 NZD
+`, b.String())
+}
+
+func Test_WriteBeancount_ShortSymbol(t *testing.T) {
+	h := listedHistory()
+	h.Instruments[fundID].Symbol = "T"
+
+	var b bytes.Buffer
+	err := export.WriteBeancount(&b, h, nil)
+
+	assert.Nil(t, err)
+	assert.Contains(t, b.String(), "2021-06-02 commodity XT\n")
+	assert.Contains(t, b.String(), "24.875 XT {2.00 NZD}")
+	assert.NotContains(t, b.String(), " T {")
+}
+
+func Test_WriteBeancount_NZDate(t *testing.T) {
+	h := listedHistory()
+	// 09:00 NZDT on 1 April and 01:00 NZST on 1 July, the previous day in UTC
+	h.Transactions[0].Timestamp = time.Date(2021, time.March, 31, 20, 0, 0, 0, time.UTC)
+	h.Instruments[fundID].Marketlastcheck = time.Date(2021, time.June, 30, 13, 0, 0, 0, time.UTC)
+
+	var b bytes.Buffer
+	err := export.WriteBeancount(&b, h, nil)
+
+	assert.Nil(t, err)
+	assert.Contains(t, b.String(), "2021-04-01 * \"Deposit\"\n")
+	assert.Contains(t, b.String(), "2021-07-01 price AIR 2.10 NZD\n")
+}
+
+func Test_WriteLedger(t *testing.T) {
+	var b bytes.Buffer
+	err := export.WriteLedger(&b, listedHistory(), nil)
+	out := b.String()
+
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(out, "commodity AIR\n    note Air New Zealand\n\naccount Assets:Bank:NZD\n"))
+	assert.Contains(t, out, "2021/06/02 * Buy AIR\n  ; id: t2\n  Assets:Sharesies:NZX:AIR                     24.875 AIR {2.00 NZD}\n")
+	assert.Contains(t, out, "  Assets:Sharesies:NZX:AIR                     -10 AIR @ 2.00 NZD\n")
+	assert.True(t, strings.HasSuffix(out, "\nP 2021/06/30 AIR 2.10 NZD\n"))
+}
+
+func Test_WriteJournal_Accounts(t *testing.T) {
+	accounts := export.DefaultAccounts
+	accounts.Cash = "Assets:Broker:{{.Currency}}:Cash"
+	accounts.Holdings = "Assets:Broker:{{.Exchange}}:{{.Currency}}:{{.Symbol}}"
+
+	var b bytes.Buffer
+	err := export.WriteBeancount(&b, listedHistory(), &export.JournalOptions{Accounts: &accounts})
+
+	assert.Nil(t, err)
+	assert.Contains(t, b.String(), "2021-06-02 open Assets:Broker:NZX:NZD:AIR\n")
+	assert.Contains(t, b.String(), "2021-06-05 open Assets:Broker:USD:Cash\n")
+
+	accounts.Fees = "Expenses:{{.Broker}}"
+	err = export.WriteLedger(io.Discard, listedHistory(), &export.JournalOptions{Accounts: &accounts})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,282 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+// DefaultBrokerID identifies Sharesies in OFX statements when OFXOptions.BrokerID is empty
+const DefaultBrokerID = "sharesies.nz"
+
+// OFXOptions configures WriteOFX
+type OFXOptions struct {
+	BrokerID  string
+	AccountID string
+	// Now is the date of the statement, time.Now when zero
+	Now time.Time
+}
+
+type ofxDoc struct {
+	XMLName    xml.Name         `xml:"OFX"`
+	SignOn     ofxSignOn        `xml:"SIGNONMSGSRSV1>SONRS"`
+	Statements []ofxStatement   `xml:"INVSTMTMSGSRSV1>INVSTMTTRNRS"`
+	Securities []ofxSecurityRef `xml:"SECLISTMSGSRSV1>SECLIST>STOCKINFO"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSignOn struct {
+	Status   ofxStatus `xml:"STATUS"`
+	DTServer string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+}
+
+type ofxStatement struct {
+	TrnUID       string        `xml:"TRNUID"`
+	Status       ofxStatus     `xml:"STATUS"`
+	DTAsOf       string        `xml:"INVSTMTRS>DTASOF"`
+	CurDef       string        `xml:"INVSTMTRS>CURDEF"`
+	BrokerID     string        `xml:"INVSTMTRS>INVACCTFROM>BROKERID"`
+	AccountID    string        `xml:"INVSTMTRS>INVACCTFROM>ACCTID"`
+	DTStart      string        `xml:"INVSTMTRS>INVTRANLIST>DTSTART"`
+	DTEnd        string        `xml:"INVSTMTRS>INVTRANLIST>DTEND"`
+	Transactions []interface{} `xml:"INVSTMTRS>INVTRANLIST>INVTRAN"`
+	AvailCash    string        `xml:"INVSTMTRS>INVBAL>AVAILCASH"`
+	Margin       string        `xml:"INVSTMTRS>INVBAL>MARGINBALANCE"`
+	Short        string        `xml:"INVSTMTRS>INVBAL>SHORTBALANCE"`
+}
+
+type ofxInvTran struct {
+	FITID   string `xml:"FITID"`
+	DTTrade string `xml:"DTTRADE"`
+	Memo    string `xml:"MEMO,omitempty"`
+}
+
+type ofxSecID struct {
+	UniqueID     string `xml:"UNIQUEID"`
+	UniqueIDType string `xml:"UNIQUEIDTYPE"`
+}
+
+type ofxTrade struct {
+	InvTran     ofxInvTran `xml:"INVTRAN"`
+	SecID       ofxSecID   `xml:"SECID"`
+	Units       string     `xml:"UNITS"`
+	UnitPrice   string     `xml:"UNITPRICE"`
+	Fees        string     `xml:"FEES"`
+	Total       string     `xml:"TOTAL"`
+	SubAcctSec  string     `xml:"SUBACCTSEC"`
+	SubAcctFund string     `xml:"SUBACCTFUND"`
+}
+
+type ofxBuyStock struct {
+	XMLName xml.Name `xml:"BUYSTOCK"`
+	Trade   ofxTrade `xml:"INVBUY"`
+	BuyType string   `xml:"BUYTYPE"`
+}
+
+type ofxSellStock struct {
+	XMLName  xml.Name `xml:"SELLSTOCK"`
+	Trade    ofxTrade `xml:"INVSELL"`
+	SellType string   `xml:"SELLTYPE"`
+}
+
+type ofxIncome struct {
+	XMLName     xml.Name   `xml:"INCOME"`
+	InvTran     ofxInvTran `xml:"INVTRAN"`
+	SecID       ofxSecID   `xml:"SECID"`
+	IncomeType  string     `xml:"INCOMETYPE"`
+	Total       string     `xml:"TOTAL"`
+	SubAcctSec  string     `xml:"SUBACCTSEC"`
+	SubAcctFund string     `xml:"SUBACCTFUND"`
+	Withholding string     `xml:"WITHHOLDING,omitempty"`
+}
+
+type ofxBankTran struct {
+	XMLName     xml.Name `xml:"INVBANKTRAN"`
+	TrnType     string   `xml:"STMTTRN>TRNTYPE"`
+	DTPosted    string   `xml:"STMTTRN>DTPOSTED"`
+	TrnAmt      string   `xml:"STMTTRN>TRNAMT"`
+	FITID       string   `xml:"STMTTRN>FITID"`
+	Name        string   `xml:"STMTTRN>NAME,omitempty"`
+	SubAcctFund string   `xml:"SUBACCTFUND"`
+}
+
+type ofxSecurityRef struct {
+	SecID  ofxSecID `xml:"SECINFO>SECID"`
+	Name   string   `xml:"SECINFO>SECNAME"`
+	Ticker string   `xml:"SECINFO>TICKER"`
+}
+
+// WriteOFX writes h as an OFX 2.2 investment statement, with one statement
+// per wallet currency. Dividends are written with their gross amount as
+// TOTAL and the tax withheld as WITHHOLDING.
+func WriteOFX(w io.Writer, h *History, opts *OFXOptions) error {
+	if opts == nil {
+		opts = &OFXOptions{}
+	}
+
+	brokerID := opts.BrokerID
+	if brokerID == "" {
+		brokerID = DefaultBrokerID
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	doc := &ofxDoc{
+		SignOn: ofxSignOn{
+			Status:   ofxStatus{Severity: "INFO"},
+			DTServer: ofxTime(now),
+			Language: "ENG",
+		},
+	}
+
+	byCurrency := map[string][]*leg{}
+	currencies := []string{}
+	for _, l := range h.legs() {
+		if _, ok := byCurrency[l.currency]; !ok {
+			currencies = append(currencies, l.currency)
+		}
+		byCurrency[l.currency] = append(byCurrency[l.currency], l)
+	}
+	sort.Strings(currencies)
+
+	for _, currency := range currencies {
+		legs := byCurrency[currency]
+		st := ofxStatement{
+			TrnUID:    strings.ToUpper(currency),
+			Status:    ofxStatus{Severity: "INFO"},
+			DTAsOf:    ofxTime(now),
+			CurDef:    strings.ToUpper(currency),
+			BrokerID:  brokerID,
+			AccountID: opts.AccountID,
+			DTStart:   ofxTime(legs[0].Timestamp),
+			DTEnd:     ofxTime(legs[len(legs)-1].Timestamp),
+			AvailCash: "0",
+			Margin:    "0",
+			Short:     "0",
+		}
+
+		for _, l := range legs {
+			st.Transactions = append(st.Transactions, ofxTransaction(h, l))
+
+			if l.Currency == currency {
+				st.AvailCash = l.Balance.String()
+			}
+		}
+
+		doc.Statements = append(doc.Statements, st)
+	}
+
+	seen := map[string]bool{}
+	for _, t := range h.Transactions {
+		if t.FundID == "" || seen[t.FundID] {
+			continue
+		}
+
+		seen[t.FundID] = true
+		doc.Securities = append(doc.Securities, ofxSecurityRef{
+			SecID:  ofxSecurity(h, t.FundID),
+			Name:   h.Name(t.FundID),
+			Ticker: h.Symbol(t.FundID),
+		})
+	}
+
+	if _, err := io.WriteString(w, ofxHeader); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func ofxTransaction(h *History, l *leg) interface{} {
+	inv := ofxInvTran{FITID: l.ID, DTTrade: ofxTime(l.Timestamp), Memo: l.Description}
+
+	switch {
+	case l.Type == sharesies.TransactionTypeBuy && l.Shares != nil && l.Price != nil:
+		return &ofxBuyStock{
+			Trade:   ofxTrade{inv, ofxSecurity(h, l.FundID), l.Shares.String(), l.Price.String(), l.Fee.String(), l.amount.String(), "CASH", "CASH"},
+			BuyType: "BUY",
+		}
+	case l.Type == sharesies.TransactionTypeSell && l.Shares != nil && l.Price != nil:
+		return &ofxSellStock{
+			Trade:    ofxTrade{inv, ofxSecurity(h, l.FundID), l.Shares.Decimal().Neg().String(), l.Price.String(), l.Fee.String(), l.amount.String(), "CASH", "CASH"},
+			SellType: "SELL",
+		}
+	case l.Type == sharesies.TransactionTypeDividend && l.FundID != "":
+		income := &ofxIncome{
+			InvTran:     inv,
+			SecID:       ofxSecurity(h, l.FundID),
+			IncomeType:  "DIV",
+			Total:       l.amount.Add(l.TaxWithheld).String(),
+			SubAcctSec:  "CASH",
+			SubAcctFund: "CASH",
+		}
+		if !l.TaxWithheld.IsZero() {
+			income.Withholding = l.TaxWithheld.String()
+		}
+		return income
+	}
+
+	bank := &ofxBankTran{
+		TrnType:     ofxBankType(l),
+		DTPosted:    ofxTime(l.Timestamp),
+		TrnAmt:      l.amount.String(),
+		FITID:       l.ID,
+		Name:        l.Description,
+		SubAcctFund: "CASH",
+	}
+
+	// The second leg of an exchange shares the ID of the first one
+	if l.currency != l.Currency {
+		bank.FITID = l.ID + "-" + l.currency
+	}
+
+	return bank
+}
+
+func ofxBankType(l *leg) string {
+	switch l.Type {
+	case sharesies.TransactionTypeDeposit:
+		return "CREDIT"
+	case sharesies.TransactionTypeWithdrawal:
+		return "DEBIT"
+	case sharesies.TransactionTypeFee:
+		return "FEE"
+	case sharesies.TransactionTypeExchange:
+		return "XFER"
+	case sharesies.TransactionTypeDividend:
+		return "DIV"
+	default:
+		return "OTHER"
+	}
+}
+
+func ofxSecurity(h *History, fundID string) ofxSecID {
+	return ofxSecID{UniqueID: h.Symbol(fundID), UniqueIDType: "TICKER"}
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
@@ -0,0 +1,93 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// DefaultQIFDateLayout is the date layout used when QIFOptions.DateLayout is empty
+const DefaultQIFDateLayout = "01/02/2006"
+
+// QIFOptions configures WriteQIF
+type QIFOptions struct {
+	// DateLayout is the time layout of the D field, written in New Zealand time
+	DateLayout string
+	// Currency restricts the export to wallet movements in a currency such
+	// as "nzd", QIF has no notion of currency so mixing them is ambiguous
+	Currency string
+}
+
+// WriteQIF writes h as a QIF investment account (!Type:Invst). Exchanges
+// are written as a transfer out of one currency and into the other.
+func WriteQIF(w io.Writer, h *History, opts *QIFOptions) error {
+	if opts == nil {
+		opts = &QIFOptions{}
+	}
+
+	layout := opts.DateLayout
+	if layout == "" {
+		layout = DefaultQIFDateLayout
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "!Type:Invst")
+
+	for _, l := range h.legs() {
+		if opts.Currency != "" && !strings.EqualFold(l.currency, opts.Currency) {
+			continue
+		}
+
+		fmt.Fprintf(bw, "D%s\n", l.Timestamp.In(sharesies.NZ).Format(layout))
+		fmt.Fprintf(bw, "N%s\n", qifAction(l))
+
+		if l.FundID != "" {
+			fmt.Fprintf(bw, "Y%s\n", h.Symbol(l.FundID))
+		}
+
+		if l.Price != nil {
+			fmt.Fprintf(bw, "I%s\n", l.Price)
+		}
+
+		if l.Shares != nil && (l.Type == sharesies.TransactionTypeBuy || l.Type == sharesies.TransactionTypeSell) {
+			fmt.Fprintf(bw, "Q%s\n", l.Shares)
+		}
+
+		// QIF amounts are unsigned, the action gives the direction
+		fmt.Fprintf(bw, "T%s\n", l.amount.Abs())
+
+		if !l.Fee.IsZero() {
+			fmt.Fprintf(bw, "O%s\n", l.Fee)
+		}
+
+		if l.Description != "" {
+			fmt.Fprintf(bw, "M%s\n", l.Description)
+		}
+
+		fmt.Fprintln(bw, "^")
+	}
+
+	return bw.Flush()
+}
+
+func qifAction(l *leg) string {
+	switch l.Type {
+	case sharesies.TransactionTypeBuy:
+		return "Buy"
+	case sharesies.TransactionTypeSell:
+		return "Sell"
+	case sharesies.TransactionTypeDividend:
+		return "Div"
+	case sharesies.TransactionTypeFee:
+		return "MiscExp"
+	}
+
+	if l.amount.Sign() < 0 {
+		return "XOut"
+	}
+
+	return "XIn"
+}
@@ -0,0 +1,154 @@
+package sharesies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LimitOrderMaxDays is how many days ahead a limit order can stay open
+const LimitOrderMaxDays = 30
+
+var ErrInvalidOrder = errors.New("invalid order")
+
+// MinimumLimitOrderValue is the smallest value, shares × price limit, accepted for a limit order
+var MinimumLimitOrderValue = NewDecimal(1, 0)
+
+type tick struct {
+	upTo Decimal
+	size Decimal
+}
+
+// tickSizes lists the price steps of each market, the last entry applies to any higher price
+var tickSizes = map[string][]tick{
+	"NZX": {
+		{upTo: NewDecimal(20, 2), size: NewDecimal(1, 3)},
+		{upTo: NewDecimal(1, 0), size: NewDecimal(5, 3)},
+		{size: NewDecimal(1, 2)},
+	},
+	"ASX": {
+		{upTo: NewDecimal(10, 2), size: NewDecimal(1, 3)},
+		{upTo: NewDecimal(2, 0), size: NewDecimal(5, 3)},
+		{size: NewDecimal(1, 2)},
+	},
+	"US": {
+		{upTo: NewDecimal(1, 0), size: NewDecimal(1, 4)},
+		{size: NewDecimal(1, 2)},
+	},
+}
+
+// TickSize returns the price step accepted by exchange (as in Company.Exchange)
+// at price, false if the exchange is unknown
+func TickSize(exchange string, price Decimal) (Decimal, bool) {
+	market := strings.ToUpper(exchange)
+	switch market {
+	case "NASDAQ", "NYSE", "NYSE ARCA", "NYSEARCA", "CBOE", "BATS", "AMEX":
+		market = "US"
+	}
+
+	ticks, ok := tickSizes[market]
+	if !ok {
+		return Decimal{}, false
+	}
+
+	for _, t := range ticks[:len(ticks)-1] {
+		if !price.GreaterThan(t.upTo) {
+			return t.size, true
+		}
+	}
+
+	return ticks[len(ticks)-1].size, true
+}
+
+// LimitOrder buys or sells Shares only at PriceLimit or better
+type LimitOrder struct {
+	FundID     string
+	Shares     Shares
+	PriceLimit Decimal
+	// GoodTill is the last day the order stays open
+	GoodTill Date
+	// Exchange the fund is listed on (Company.Exchange), enables tick size validation
+	Exchange string
+}
+
+// Validate checks the order against Sharesies rules as of today, the New Zealand date
+func (o *LimitOrder) Validate(today Date) error {
+	if o.FundID == "" {
+		return fmt.Errorf("%w: fund is required", ErrInvalidOrder)
+	}
+
+	if o.Shares.Sign() <= 0 {
+		return fmt.Errorf("%w: shares must be positive", ErrInvalidOrder)
+	}
+
+	if o.Shares.Round(SharesPlaces).Cmp(o.Shares) != 0 {
+		return fmt.Errorf("%w: shares can have at most %d decimal places", ErrInvalidOrder, SharesPlaces)
+	}
+
+	if o.PriceLimit.Sign() <= 0 {
+		return fmt.Errorf("%w: price limit must be positive", ErrInvalidOrder)
+	}
+
+	if size, ok := TickSize(o.Exchange, o.PriceLimit); ok {
+		if steps := o.PriceLimit.Div(size, 0); !steps.Mul(size).Equal(o.PriceLimit) {
+			return fmt.Errorf("%w: price limit must be a multiple of %s on %s", ErrInvalidOrder, size, o.Exchange)
+		}
+	}
+
+	if o.Shares.Value(o.PriceLimit).LessThan(MinimumLimitOrderValue) {
+		return fmt.Errorf("%w: order value must be at least %s", ErrInvalidOrder, MinimumLimitOrderValue)
+	}
+
+	if o.GoodTill.Before(today) {
+		return fmt.Errorf("%w: good till date is in the past", ErrInvalidOrder)
+	}
+
+	if o.GoodTill.After(today.AddDays(LimitOrderMaxDays)) {
+		return fmt.Errorf("%w: good till date can be at most %d days ahead", ErrInvalidOrder, LimitOrderMaxDays)
+	}
+
+	return nil
+}
+
+// CostBuyLimit return Cost to buy shares at the order price limit or lower
+func (s *Sharesies) CostBuyLimit(ctx context.Context, order *LimitOrder) (*CostBuyResponse, error) {
+	return s.account().CostBuyLimit(ctx, order)
+}
+
+// CostSellLimit return Cost to sell shares at the order price limit or higher
+func (s *Sharesies) CostSellLimit(ctx context.Context, order *LimitOrder) (*CostSellResponse, error) {
+	return s.account().CostSellLimit(ctx, order)
+}
+
+// CostBuyLimit return Cost to buy shares at the order price limit or lower
+func (a *Account) CostBuyLimit(ctx context.Context, order *LimitOrder) (*CostBuyResponse, error) {
+	if err := order.Validate(Today()); err != nil {
+		return nil, err
+	}
+
+	shares := order.Shares.Round(SharesPlaces)
+	goodTill := order.GoodTill
+	return a.costBuy(ctx, order.FundID, &OrderBuy{
+		Type:        OrderTypeShareLimit,
+		ShareAmount: &shares,
+		PriceLimit:  &order.PriceLimit,
+		ExpiryDate:  &goodTill,
+	})
+}
+
+// CostSellLimit return Cost to sell shares at the order price limit or higher
+func (a *Account) CostSellLimit(ctx context.Context, order *LimitOrder) (*CostSellResponse, error) {
+	if err := order.Validate(Today()); err != nil {
+		return nil, err
+	}
+
+	shares := order.Shares.Round(SharesPlaces)
+	goodTill := order.GoodTill
+	return a.costSell(ctx, order.FundID, &OrderSell{
+		Type:        OrderTypeShareLimit,
+		ShareAmount: &shares,
+		PriceLimit:  &order.PriceLimit,
+		ExpiryDate:  &goodTill,
+	})
+}
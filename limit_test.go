@@ -0,0 +1,77 @@
+package sharesies_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+func Test_TickSize(t *testing.T) {
+	tests := []struct {
+		exchange string
+		price    string
+		want     string
+	}{
+		{"NZX", "0.15", "0.001"},
+		{"NZX", "0.20", "0.001"},
+		{"NZX", "0.85", "0.005"},
+		{"NZX", "2.50", "0.01"},
+		{"ASX", "0.05", "0.001"},
+		{"ASX", "1.50", "0.005"},
+		{"ASX", "2.01", "0.01"},
+		{"NASDAQ", "0.50", "0.0001"},
+		{"NYSE", "150.25", "0.01"},
+	}
+
+	for _, tt := range tests {
+		size, ok := sharesies.TickSize(tt.exchange, sharesies.MustParseDecimal(tt.price))
+
+		assert.True(t, ok, tt.exchange)
+		assert.Equal(t, tt.want, size.String(), tt.exchange+" "+tt.price)
+	}
+
+	_, ok := sharesies.TickSize("LSE", sharesies.NewDecimal(1, 0))
+	assert.False(t, ok)
+}
+
+func Test_LimitOrder_Validate(t *testing.T) {
+	today := sharesies.Date{Year: 2021, Month: time.June, Day: 1}
+	valid := func() *sharesies.LimitOrder {
+		return &sharesies.LimitOrder{
+			FundID:     "fund",
+			Shares:     sharesies.MustParseShares("10"),
+			PriceLimit: sharesies.MustParseDecimal("2.55"),
+			GoodTill:   today.AddDays(7),
+			Exchange:   "NZX",
+		}
+	}
+
+	assert.Nil(t, valid().Validate(today))
+
+	tests := map[string]func(o *sharesies.LimitOrder){
+		"no fund":         func(o *sharesies.LimitOrder) { o.FundID = "" },
+		"no shares":       func(o *sharesies.LimitOrder) { o.Shares = sharesies.Shares{} },
+		"share places":    func(o *sharesies.LimitOrder) { o.Shares = sharesies.MustParseShares("1.0000001") },
+		"no price":        func(o *sharesies.LimitOrder) { o.PriceLimit = sharesies.Decimal{} },
+		"tick size":       func(o *sharesies.LimitOrder) { o.PriceLimit = sharesies.MustParseDecimal("2.555") },
+		"minimum":         func(o *sharesies.LimitOrder) { o.Shares = sharesies.MustParseShares("0.1") },
+		"past good till":  func(o *sharesies.LimitOrder) { o.GoodTill = today.AddDays(-1) },
+		"too far ahead":   func(o *sharesies.LimitOrder) { o.GoodTill = today.AddDays(sharesies.LimitOrderMaxDays + 1) },
+		"unset good till": func(o *sharesies.LimitOrder) { o.GoodTill = sharesies.Date{} },
+	}
+
+	for name, change := range tests {
+		o := valid()
+		change(o)
+
+		assert.ErrorIs(t, o.Validate(today), sharesies.ErrInvalidOrder, name)
+	}
+
+	o := valid()
+	o.Exchange = ""
+	o.PriceLimit = sharesies.MustParseDecimal("2.555")
+	assert.Nil(t, o.Validate(today), "tick size is only checked for known exchanges")
+}
@@ -0,0 +1,21 @@
+// Package lots tracks the cost basis of shares lot by lot, reporting the
+// realised gain of every sale and the unrealised gain of what is still held.
+//
+// A Tracker consumes buy, sell and transfer events in date order and matches
+// sales to lots first in first out, last in first out, by specific lot or
+// at average cost:
+//
+//	t := lots.NewTracker(lots.FIFO)
+//	for _, tx := range h.Transactions {
+//		if e, ok := lots.EventFromTransaction(tx); ok {
+//			e.Rate = nzdPer(tx.Currency, tx.Timestamp)
+//			if err := t.Add(e); err != nil {
+//				log.Fatal(err)
+//			}
+//		}
+//	}
+//
+//	for _, s := range t.Sales() {
+//		fmt.Println(s.FundID, s.Gain)
+//	}
+package lots
@@ -0,0 +1,420 @@
+package lots
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// Method decides which lots a sale or transfer out consumes
+type Method string
+
+const (
+	// FIFO sells the oldest lots first
+	FIFO Method = "fifo"
+	// LIFO sells the newest lots first
+	LIFO Method = "lifo"
+	// Specific sells the lots listed in Event.LotIDs
+	Specific Method = "specific"
+	// Average pools every purchase of a fund into a single lot at average cost
+	Average Method = "average"
+)
+
+const (
+	EventBuy         = "buy"
+	EventSell        = "sell"
+	EventTransferIn  = "transfer_in"
+	EventTransferOut = "transfer_out"
+)
+
+// costPlaces is the precision of cost split between partially sold lots
+const costPlaces = 10
+
+var (
+	ErrInsufficientShares = errors.New("insufficient shares")
+	ErrUnknownLot         = errors.New("unknown lot")
+	ErrDuplicateLot       = errors.New("lot selected more than once")
+	ErrNoLotsSelected     = errors.New("no lots selected")
+	ErrUnknownEvent       = errors.New("unknown event type")
+	ErrUnknownMethod      = errors.New("unknown method")
+	ErrUnknownInstrument  = errors.New("unknown instrument")
+)
+
+// Event changes the shares held of a fund
+type Event struct {
+	ID     string
+	Type   string
+	Time   time.Time
+	FundID string
+	Shares sharesies.Shares
+	// Price is per share in Currency, for a transfer in it is the cost basis carried over
+	Price sharesies.Decimal
+	Fee   sharesies.Decimal
+	// Currency the event was traded in, such as "usd"
+	Currency string
+	// Rate converts Currency to the base currency at the time of the event, 1 when zero
+	Rate sharesies.Decimal
+	// LotIDs are the lots a sale or transfer out consumes with the Specific method, in order
+	LotIDs []string
+}
+
+// Lot is a parcel of shares acquired at the same time and cost
+type Lot struct {
+	ID       string
+	FundID   string
+	Acquired time.Time
+	Currency string
+	// Shares still held of the lot
+	Shares sharesies.Shares
+	// LocalCost is the cost of Shares in Currency, fees included
+	LocalCost sharesies.Decimal
+	// Cost is the cost of Shares in the base currency at the acquisition rates
+	Cost sharesies.Decimal
+}
+
+// LotSale is the part of a lot consumed by a sale
+type LotSale struct {
+	LotID    string
+	Acquired time.Time
+	Shares   sharesies.Shares
+	Cost     sharesies.Decimal
+}
+
+// Sale is the realised gain of a sell event, amounts are in the base currency
+type Sale struct {
+	EventID string
+	FundID  string
+	Time    time.Time
+	Shares  sharesies.Shares
+	// Proceeds is the sale value less fees
+	Proceeds sharesies.Decimal
+	Cost     sharesies.Decimal
+	Gain     sharesies.Decimal
+	Lots     []LotSale
+}
+
+// Holding is the unrealised gain of the shares held of a fund, amounts are in the base currency
+type Holding struct {
+	FundID string
+	Shares sharesies.Shares
+	Cost   sharesies.Decimal
+	Value  sharesies.Decimal
+	Gain   sharesies.Decimal
+}
+
+// Tracker matches sales to lots with its Method
+type Tracker struct {
+	method Method
+	lots   map[string][]*Lot
+	sales  []*Sale
+	seq    int
+}
+
+// NewTracker returns an empty Tracker using method
+func NewTracker(method Method) *Tracker {
+	return &Tracker{method: method, lots: map[string][]*Lot{}}
+}
+
+// EventFromTransaction converts a buy or sell Transaction to an Event, false
+// for any other transaction. Rate is left for the caller to set.
+func EventFromTransaction(t *sharesies.Transaction) (*Event, bool) {
+	if t.Shares == nil || t.Price == nil {
+		return nil, false
+	}
+
+	var typ string
+	switch t.Type {
+	case sharesies.TransactionTypeBuy:
+		typ = EventBuy
+	case sharesies.TransactionTypeSell:
+		typ = EventSell
+	default:
+		return nil, false
+	}
+
+	return &Event{
+		ID:       t.ID,
+		Type:     typ,
+		Time:     t.Timestamp,
+		FundID:   t.FundID,
+		Shares:   *t.Shares,
+		Price:    *t.Price,
+		Fee:      t.Fee,
+		Currency: t.Currency,
+	}, true
+}
+
+// Add applies e, events must be added in date order
+func (t *Tracker) Add(e *Event) error {
+	rate := e.Rate
+	if rate.IsZero() {
+		rate = sharesies.NewDecimal(1, 0)
+	}
+
+	switch e.Type {
+	case EventBuy, EventTransferIn:
+		local := e.Shares.Value(e.Price)
+		if e.Type == EventBuy {
+			local = local.Add(e.Fee)
+		}
+
+		return t.acquire(&Lot{
+			ID:        t.lotID(e),
+			FundID:    e.FundID,
+			Acquired:  e.Time,
+			Currency:  e.Currency,
+			Shares:    e.Shares,
+			LocalCost: local,
+			Cost:      local.Mul(rate),
+		})
+	case EventSell:
+		consumed, err := t.dispose(e)
+		if err != nil {
+			return err
+		}
+
+		s := &Sale{
+			EventID:  e.ID,
+			FundID:   e.FundID,
+			Time:     e.Time,
+			Shares:   e.Shares,
+			Proceeds: e.Shares.Value(e.Price).Sub(e.Fee).Mul(rate),
+			Lots:     consumed,
+		}
+
+		for _, l := range consumed {
+			s.Cost = s.Cost.Add(l.Cost)
+		}
+		s.Gain = s.Proceeds.Sub(s.Cost)
+
+		t.sales = append(t.sales, s)
+		return nil
+	case EventTransferOut:
+		_, err := t.dispose(e)
+		return err
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownEvent, e.Type)
+	}
+}
+
+func (t *Tracker) lotID(e *Event) string {
+	t.seq++
+	if e.ID != "" {
+		return e.ID
+	}
+
+	return fmt.Sprintf("%s-%d", e.FundID, t.seq)
+}
+
+func (t *Tracker) acquire(l *Lot) error {
+	switch t.method {
+	case FIFO, LIFO, Specific:
+		t.lots[l.FundID] = append(t.lots[l.FundID], l)
+	case Average:
+		lots := t.lots[l.FundID]
+		if len(lots) == 0 {
+			l.ID = l.FundID
+			t.lots[l.FundID] = []*Lot{l}
+			return nil
+		}
+
+		pool := lots[0]
+		pool.Shares = pool.Shares.Add(l.Shares)
+		pool.LocalCost = pool.LocalCost.Add(l.LocalCost)
+		pool.Cost = pool.Cost.Add(l.Cost)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownMethod, t.method)
+	}
+
+	return nil
+}
+
+// dispose removes e.Shares from the lots chosen by the method
+func (t *Tracker) dispose(e *Event) ([]LotSale, error) {
+	lots := t.lots[e.FundID]
+
+	held := sharesies.Shares{}
+	for _, l := range lots {
+		held = held.Add(l.Shares)
+	}
+
+	if held.Cmp(e.Shares) < 0 {
+		return nil, fmt.Errorf("%w: selling %s of %s holding %s", ErrInsufficientShares, e.Shares, e.FundID, held)
+	}
+
+	order, err := t.order(e, lots)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := sharesies.Shares{}
+	for _, l := range order {
+		selected = selected.Add(l.Shares)
+	}
+
+	if selected.Cmp(e.Shares) < 0 {
+		return nil, fmt.Errorf("%w: selected lots hold %s of %s", ErrInsufficientShares, selected, e.Shares)
+	}
+
+	consumed := []LotSale{}
+	remaining := e.Shares
+	for _, l := range order {
+		if remaining.Sign() <= 0 {
+			break
+		}
+
+		take := l.Shares
+		if take.Cmp(remaining) > 0 {
+			take = remaining
+		}
+
+		cost, local := l.Cost, l.LocalCost
+		if take.Cmp(l.Shares) < 0 {
+			cost = l.Cost.Mul(take.Decimal()).Div(l.Shares.Decimal(), costPlaces)
+			local = l.LocalCost.Mul(take.Decimal()).Div(l.Shares.Decimal(), costPlaces)
+		}
+
+		l.Shares = l.Shares.Sub(take)
+		l.Cost = l.Cost.Sub(cost)
+		l.LocalCost = l.LocalCost.Sub(local)
+		remaining = remaining.Sub(take)
+
+		consumed = append(consumed, LotSale{LotID: l.ID, Acquired: l.Acquired, Shares: take, Cost: cost})
+	}
+
+	if remaining.Sign() > 0 {
+		return nil, fmt.Errorf("%w: %s of %s left unsold", ErrInsufficientShares, remaining, e.Shares)
+	}
+
+	kept := lots[:0]
+	for _, l := range lots {
+		if l.Shares.Sign() > 0 {
+			kept = append(kept, l)
+		}
+	}
+	t.lots[e.FundID] = kept
+
+	return consumed, nil
+}
+
+// order returns the lots in the order the method consumes them
+func (t *Tracker) order(e *Event, lots []*Lot) ([]*Lot, error) {
+	switch t.method {
+	case FIFO, Average:
+		return lots, nil
+	case LIFO:
+		order := make([]*Lot, len(lots))
+		for i, l := range lots {
+			order[len(lots)-1-i] = l
+		}
+		return order, nil
+	case Specific:
+		if len(e.LotIDs) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNoLotsSelected, e.ID)
+		}
+
+		byID := map[string]*Lot{}
+		for _, l := range lots {
+			byID[l.ID] = l
+		}
+
+		order := make([]*Lot, 0, len(e.LotIDs))
+		seen := map[string]bool{}
+		for _, id := range e.LotIDs {
+			l, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownLot, id)
+			}
+
+			if seen[id] {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicateLot, id)
+			}
+			seen[id] = true
+
+			order = append(order, l)
+		}
+		return order, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMethod, t.method)
+	}
+}
+
+// Lots returns a copy of the open lots of fundID, oldest first
+func (t *Tracker) Lots(fundID string) []Lot {
+	lots := make([]Lot, 0, len(t.lots[fundID]))
+	for _, l := range t.lots[fundID] {
+		lots = append(lots, *l)
+	}
+
+	return lots
+}
+
+// Sales returns the realised gain of every sale added so far
+func (t *Tracker) Sales() []*Sale {
+	return t.sales
+}
+
+// Realised returns the total realised gain of the sales between from and to, both inclusive when set
+func (t *Tracker) Realised(from, to time.Time) sharesies.Decimal {
+	total := sharesies.Decimal{}
+	for _, s := range t.sales {
+		if (!from.IsZero() && s.Time.Before(from)) || (!to.IsZero() && s.Time.After(to)) {
+			continue
+		}
+
+		total = total.Add(s.Gain)
+	}
+
+	return total
+}
+
+// Unrealised returns the gain of the shares held of fundID valued at price
+// per share, converted to the base currency with rate (1 when zero)
+func (t *Tracker) Unrealised(fundID string, price, rate sharesies.Decimal) *Holding {
+	if rate.IsZero() {
+		rate = sharesies.NewDecimal(1, 0)
+	}
+
+	h := &Holding{FundID: fundID}
+	for _, l := range t.lots[fundID] {
+		h.Shares = h.Shares.Add(l.Shares)
+		h.Cost = h.Cost.Add(l.Cost)
+	}
+
+	h.Value = h.Shares.Value(price).Mul(rate)
+	h.Gain = h.Value.Sub(h.Cost)
+	return h
+}
+
+// Holdings returns the unrealised gain of every fund held, valued at the
+// Company.Marketprice found in instruments and converted with rate, which
+// returns the base currency value of one unit of a currency such as "usd"
+func (t *Tracker) Holdings(instruments map[string]*sharesies.Company, rate func(currency string) (sharesies.Decimal, error)) ([]*Holding, error) {
+	ids := make([]string, 0, len(t.lots))
+	for fundID, lots := range t.lots {
+		if len(lots) > 0 {
+			ids = append(ids, fundID)
+		}
+	}
+	sort.Strings(ids)
+
+	holdings := make([]*Holding, 0, len(ids))
+	for _, fundID := range ids {
+		c, ok := instruments[fundID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownInstrument, fundID)
+		}
+
+		r, err := rate(t.lots[fundID][0].Currency)
+		if err != nil {
+			return nil, err
+		}
+
+		holdings = append(holdings, t.Unrealised(fundID, c.Marketprice, r))
+	}
+
+	return holdings, nil
+}
@@ -0,0 +1,173 @@
+package lots_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/lots"
+)
+
+const fundID = "fund"
+
+func decimal(s string) sharesies.Decimal {
+	return sharesies.MustParseDecimal(s)
+}
+
+func event(typ string, day int, id, shares, price string) *lots.Event {
+	return &lots.Event{
+		ID:       id,
+		Type:     typ,
+		Time:     time.Date(2021, time.June, day, 0, 0, 0, 0, time.UTC),
+		FundID:   fundID,
+		Shares:   sharesies.MustParseShares(shares),
+		Price:    decimal(price),
+		Currency: "nzd",
+	}
+}
+
+func track(t *testing.T, method lots.Method, sell *lots.Event) *lots.Tracker {
+	tr := lots.NewTracker(method)
+	assert.Nil(t, tr.Add(event(lots.EventBuy, 1, "a", "10", "1")))
+	assert.Nil(t, tr.Add(event(lots.EventBuy, 2, "b", "10", "2")))
+	assert.Nil(t, tr.Add(event(lots.EventBuy, 3, "c", "10", "3")))
+	assert.Nil(t, tr.Add(sell))
+
+	return tr
+}
+
+func Test_Tracker_Methods(t *testing.T) {
+	specific := event(lots.EventSell, 4, "s", "15", "4")
+	specific.LotIDs = []string{"b", "a"}
+
+	cases := []struct {
+		method lots.Method
+		sell   *lots.Event
+		cost   string
+		lots   []string
+	}{
+		{lots.FIFO, event(lots.EventSell, 4, "s", "15", "4"), "20", []string{"b", "c"}},
+		{lots.LIFO, event(lots.EventSell, 4, "s", "15", "4"), "40", []string{"a", "b"}},
+		{lots.Specific, specific, "25", []string{"a", "c"}},
+		{lots.Average, event(lots.EventSell, 4, "s", "15", "4"), "30", []string{fundID}},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.method), func(t *testing.T) {
+			tr := track(t, c.method, c.sell)
+
+			sales := tr.Sales()
+			assert.Len(t, sales, 1)
+			assert.True(t, decimal(c.cost).Equal(sales[0].Cost), sales[0].Cost.String())
+			assert.True(t, decimal("60").Equal(sales[0].Proceeds))
+			assert.True(t, decimal("60").Sub(decimal(c.cost)).Equal(sales[0].Gain))
+
+			ids := []string{}
+			for _, l := range tr.Lots(fundID) {
+				ids = append(ids, l.ID)
+			}
+			assert.Equal(t, c.lots, ids)
+
+			h := tr.Unrealised(fundID, decimal("5"), sharesies.Decimal{})
+			assert.Equal(t, "15", h.Shares.String())
+			assert.True(t, decimal("60").Sub(decimal(c.cost)).Equal(h.Cost), h.Cost.String())
+			assert.True(t, decimal("75").Equal(h.Value))
+		})
+	}
+}
+
+func Test_Tracker_CurrencyAndFees(t *testing.T) {
+	tr := lots.NewTracker(lots.FIFO)
+
+	buy := event(lots.EventBuy, 1, "a", "10", "100")
+	buy.Currency, buy.Fee, buy.Rate = "usd", decimal("2"), decimal("1.5")
+	assert.Nil(t, tr.Add(buy))
+
+	sell := event(lots.EventSell, 2, "s", "4", "110")
+	sell.Currency, sell.Fee, sell.Rate = "usd", decimal("1"), decimal("1.4")
+	assert.Nil(t, tr.Add(sell))
+
+	s := tr.Sales()[0]
+	assert.True(t, decimal("614.6").Equal(s.Proceeds), s.Proceeds.String())
+	assert.True(t, decimal("601.2").Equal(s.Cost), s.Cost.String())
+	assert.True(t, decimal("13.4").Equal(s.Gain), s.Gain.String())
+	assert.Equal(t, "a", s.Lots[0].LotID)
+
+	l := tr.Lots(fundID)[0]
+	assert.Equal(t, "6", l.Shares.String())
+	assert.True(t, decimal("601.2").Equal(l.LocalCost), l.LocalCost.String())
+	assert.True(t, decimal("901.8").Equal(l.Cost), l.Cost.String())
+
+	holdings, err := tr.Holdings(
+		map[string]*sharesies.Company{fundID: {ID: fundID, Marketprice: decimal("120")}},
+		func(currency string) (sharesies.Decimal, error) { return decimal("1.5"), nil },
+	)
+	assert.Nil(t, err)
+	assert.True(t, decimal("1080").Equal(holdings[0].Value))
+	assert.True(t, decimal("178.2").Equal(holdings[0].Gain), holdings[0].Gain.String())
+}
+
+func Test_Tracker_Transfers(t *testing.T) {
+	tr := lots.NewTracker(lots.FIFO)
+
+	assert.Nil(t, tr.Add(event(lots.EventTransferIn, 1, "in", "10", "2")))
+	assert.Nil(t, tr.Add(event(lots.EventTransferOut, 2, "out", "4", "0")))
+
+	assert.Empty(t, tr.Sales())
+	l := tr.Lots(fundID)[0]
+	assert.Equal(t, "6", l.Shares.String())
+	assert.True(t, decimal("12").Equal(l.Cost), l.Cost.String())
+}
+
+func Test_Tracker_Errors(t *testing.T) {
+	tr := lots.NewTracker(lots.Specific)
+	assert.Nil(t, tr.Add(event(lots.EventBuy, 1, "a", "10", "1")))
+
+	err := tr.Add(event(lots.EventSell, 2, "s", "11", "1"))
+	assert.True(t, errors.Is(err, lots.ErrInsufficientShares))
+
+	err = tr.Add(event(lots.EventSell, 2, "s", "1", "1"))
+	assert.True(t, errors.Is(err, lots.ErrNoLotsSelected))
+
+	sell := event(lots.EventSell, 2, "s", "1", "1")
+	sell.LotIDs = []string{"z"}
+	assert.True(t, errors.Is(tr.Add(sell), lots.ErrUnknownLot))
+
+	assert.True(t, errors.Is(tr.Add(event("split", 2, "x", "1", "1")), lots.ErrUnknownEvent))
+
+	_, err = tr.Holdings(nil, func(string) (sharesies.Decimal, error) { return decimal("1"), nil })
+	assert.True(t, errors.Is(err, lots.ErrUnknownInstrument))
+	assert.Equal(t, "10", tr.Lots(fundID)[0].Shares.String())
+}
+
+func Test_Tracker_DuplicateLots(t *testing.T) {
+	tr := lots.NewTracker(lots.Specific)
+	assert.Nil(t, tr.Add(event(lots.EventBuy, 1, "a", "10", "1")))
+	assert.Nil(t, tr.Add(event(lots.EventBuy, 2, "b", "10", "2")))
+
+	sell := event(lots.EventSell, 3, "s", "15", "3")
+	sell.LotIDs = []string{"a", "a"}
+	assert.True(t, errors.Is(tr.Add(sell), lots.ErrDuplicateLot))
+
+	assert.Empty(t, tr.Sales())
+	assert.Len(t, tr.Lots(fundID), 2)
+	assert.Equal(t, "10", tr.Lots(fundID)[0].Shares.String())
+}
+
+func Test_EventFromTransaction(t *testing.T) {
+	shares, price := sharesies.MustParseShares("3"), decimal("2.5")
+
+	e, ok := lots.EventFromTransaction(&sharesies.Transaction{
+		ID: "t", Type: sharesies.TransactionTypeSell, FundID: fundID, Currency: "usd",
+		Shares: &shares, Price: &price, Fee: decimal("0.1"),
+	})
+	assert.True(t, ok)
+	assert.Equal(t, lots.EventSell, e.Type)
+	assert.Equal(t, "usd", e.Currency)
+
+	_, ok = lots.EventFromTransaction(&sharesies.Transaction{Type: sharesies.TransactionTypeDividend})
+	assert.False(t, ok)
+}
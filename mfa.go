@@ -0,0 +1,78 @@
+package sharesies
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ResponseTypeMFARequired is the login response type of accounts with 2FA enabled
+const ResponseTypeMFARequired = "identity_mfa_required"
+
+var ErrMFARequired = errors.New("multi-factor authentication required")
+var ErrNoPendingMFA = errors.New("no multi-factor authentication pending")
+
+// MFAChallenge is returned by Authenticate when the login must be completed
+// with SubmitMFA, it matches ErrMFARequired when compared with errors.Is
+type MFAChallenge struct {
+	Type string
+}
+
+func (c *MFAChallenge) Error() string {
+	return ErrMFARequired.Error()
+}
+
+// Is reports whether target is ErrMFARequired
+func (c *MFAChallenge) Is(target error) bool {
+	return target == ErrMFARequired
+}
+
+// SubmitMFA completes a login interrupted by an MFAChallenge with the one-time code
+func (s *Sharesies) SubmitMFA(ctx context.Context, code string) (*ProfileResponse, error) {
+	s.mu.Lock()
+	creds := s.pendingMFA
+	s.mu.Unlock()
+
+	if creds == nil {
+		return nil, ErrNoPendingMFA
+	}
+
+	return s.login(ctx, creds, code)
+}
+
+func (s *Sharesies) login(ctx context.Context, creds *Credentials, code string) (*ProfileResponse, error) {
+	p := &ProfileResponse{}
+	body := Map{"email": creds.Username, "password": creds.Password, "remember": true}
+	if code != "" {
+		body["mfa_token"] = code
+	}
+
+	err := s.request(ctx, http.MethodPost, nil, s.endpoints().IdentityLogin, &body, p)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.Authenticated {
+		if p.Type == ResponseTypeMFARequired {
+			s.mu.Lock()
+			s.pendingMFA = creds
+			s.mu.Unlock()
+
+			return nil, &MFAChallenge{Type: p.Type}
+		}
+
+		return nil, ErrAuthentication
+	}
+
+	s.mu.Lock()
+	s.pendingMFA = nil
+	s.creds = creds
+	s.mu.Unlock()
+
+	err = s.authenticated(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
@@ -0,0 +1,117 @@
+package sharesies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var ErrNoOrder = errors.New("response has no order")
+
+// IsTerminal reports whether the order reached a final state and will not change anymore
+func (o *Order) IsTerminal() bool {
+	switch o.State {
+	case OrderStateFulfilled, OrderStateCancelled, OrderStateRejected, OrderStateExpired:
+		return true
+	default:
+		return false
+	}
+}
+
+// PlacedOrder returns the order of p, the profile returned by Buy or Sell,
+// for fundID on side that was not in before, the profile fetched before
+// placing it. It is nil when no single order matches, such as when the order
+// was filled and left the profile or another one was placed concurrently.
+func (p *ProfileResponse) PlacedOrder(before *ProfileResponse, fundID, side string) *Order {
+	known := map[string]bool{}
+	if before != nil {
+		for _, o := range before.Orders {
+			known[o.ID] = true
+		}
+	}
+
+	var placed *Order
+	for _, o := range p.Orders {
+		if known[o.ID] || o.FundID != fundID || o.Side != side {
+			continue
+		}
+
+		if placed != nil {
+			return nil
+		}
+		placed = o
+	}
+
+	return placed
+}
+
+// PendingOrders returns the orders still waiting to be filled
+func (s *Sharesies) PendingOrders(ctx context.Context) ([]*Order, error) {
+	return s.account().PendingOrders(ctx)
+}
+
+// OrderHistory returns the orders that reached a final state
+func (s *Sharesies) OrderHistory(ctx context.Context) ([]*Order, error) {
+	return s.account().OrderHistory(ctx)
+}
+
+// Order returns the order with id
+func (s *Sharesies) Order(ctx context.Context, id string) (*Order, error) {
+	return s.account().Order(ctx, id)
+}
+
+// CancelOrder cancels a pending order and returns it in its new state
+func (s *Sharesies) CancelOrder(ctx context.Context, id string) (*Order, error) {
+	return s.account().CancelOrder(ctx, id)
+}
+
+// PendingOrders returns the orders still waiting to be filled
+func (a *Account) PendingOrders(ctx context.Context) ([]*Order, error) {
+	return a.orders(ctx, OrderStatePending, OrderStateProcessing)
+}
+
+// OrderHistory returns the orders that reached a final state
+func (a *Account) OrderHistory(ctx context.Context) ([]*Order, error) {
+	return a.orders(ctx, OrderStateFulfilled, OrderStateCancelled, OrderStateRejected, OrderStateExpired)
+}
+
+func (a *Account) orders(ctx context.Context, states ...string) ([]*Order, error) {
+	actingAsID, err := a.actingAsID()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &OrdersResponse{}
+	or := &OrdersRequest{ActingAsID: actingAsID, States: states}
+
+	err = a.s.authRequest(ctx, http.MethodPost, a.s.endpoints().Orders, false, or, r)
+	return r.Orders, err
+}
+
+// Order returns the order with id
+func (a *Account) Order(ctx context.Context, id string) (*Order, error) {
+	return a.order(ctx, a.s.endpoints().Order, id)
+}
+
+// CancelOrder cancels a pending order and returns it in its new state
+func (a *Account) CancelOrder(ctx context.Context, id string) (*Order, error) {
+	return a.order(ctx, a.s.endpoints().CancelOrder, id)
+}
+
+func (a *Account) order(ctx context.Context, url, id string) (*Order, error) {
+	actingAsID, err := a.actingAsID()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &OrderResponse{}
+	or := &OrderRequest{ActingAsID: actingAsID, OrderID: id}
+
+	err = a.s.authRequest(ctx, http.MethodPost, url, false, or, r)
+	if err == nil && r.Order == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoOrder, id)
+	}
+
+	return r.Order, err
+}
@@ -0,0 +1,94 @@
+package sharesies_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+func Test_Order_IsTerminal(t *testing.T) {
+	states := map[string]bool{
+		sharesies.OrderStatePending:    false,
+		sharesies.OrderStateProcessing: false,
+		sharesies.OrderStateFulfilled:  true,
+		sharesies.OrderStateCancelled:  true,
+		sharesies.OrderStateRejected:   true,
+		sharesies.OrderStateExpired:    true,
+	}
+
+	for state, terminal := range states {
+		o := &sharesies.Order{State: state}
+		assert.Equal(t, terminal, o.IsTerminal(), state)
+	}
+}
+
+func Test_PlacedOrder(t *testing.T) {
+	pending := &sharesies.Order{ID: "pending", FundID: "fund", Side: sharesies.OrderSideBuy}
+	autoinvest := &sharesies.Order{ID: "autoinvest", FundID: "other", Side: sharesies.OrderSideBuy}
+	placed := &sharesies.Order{ID: "placed", FundID: "fund", Side: sharesies.OrderSideBuy}
+
+	before := &sharesies.ProfileResponse{Orders: []*sharesies.Order{pending}}
+	after := &sharesies.ProfileResponse{Orders: []*sharesies.Order{autoinvest, placed, pending}}
+
+	assert.Equal(t, "placed", after.PlacedOrder(before, "fund", sharesies.OrderSideBuy).ID)
+	assert.Nil(t, after.PlacedOrder(before, "fund", sharesies.OrderSideSell))
+	assert.Nil(t, after.PlacedOrder(nil, "fund", sharesies.OrderSideBuy), "ambiguous without before")
+	assert.Nil(t, before.PlacedOrder(before, "fund", sharesies.OrderSideBuy), "filled and left the profile")
+}
+
+func Test_Orders(t *testing.T) {
+	_, acc, s := newFakeServer(t)
+	ctx := context.Background()
+
+	costBuy, _ := s.CostBuy(ctx, fakeFundID, sharesies.NewDecimal(50, 0))
+	first, err := s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+
+	filled := first.PlacedOrder(nil, fakeFundID, sharesies.OrderSideBuy)
+	assert.Equal(t, sharesies.OrderStateFulfilled, filled.State)
+	assert.Equal(t, "2.00", filled.FillPrice.String())
+	assert.Equal(t, "24.875000", filled.FilledShares.String())
+	assert.True(t, filled.IsTerminal())
+
+	costLimit, _ := s.CostBuyLimit(ctx, &sharesies.LimitOrder{
+		FundID:     fakeFundID,
+		Shares:     sharesies.MustParseShares("10"),
+		PriceLimit: sharesies.MustParseDecimal("1.50"),
+		GoodTill:   sharesies.Today(),
+	})
+	p, err := s.Buy(ctx, costLimit)
+	assert.Nil(t, err)
+
+	limit := p.PlacedOrder(first, fakeFundID, sharesies.OrderSideBuy)
+	assert.Equal(t, sharesies.OrderStatePending, limit.State)
+	assert.Equal(t, "34.92", acc.Balance("nzd").String())
+
+	pending, err := s.PendingOrders(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, limit.ID, pending[0].ID)
+
+	history, err := s.OrderHistory(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, history, 1)
+	assert.Equal(t, filled.ID, history[0].ID)
+
+	o, err := s.Order(ctx, limit.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.50", o.PriceLimit.String())
+	assert.Nil(t, o.FillPrice)
+
+	cancelled, err := s.CancelOrder(ctx, limit.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, sharesies.OrderStateCancelled, cancelled.State)
+	assert.Equal(t, "50.00", acc.Balance("nzd").String())
+
+	_, err = s.CancelOrder(ctx, limit.ID)
+	assert.True(t, sharesies.IsOrderNotPending(err))
+
+	_, err = s.Order(ctx, "unknown")
+	assert.True(t, sharesies.IsOrderNotFound(err))
+}
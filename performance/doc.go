@@ -0,0 +1,18 @@
+// Package performance measures investment returns from the Sharesies
+// transaction history and share prices.
+//
+// Analyse reports, per holding and for the whole portfolio, the
+// time-weighted return, which ignores when money was added or taken out, and
+// the money-weighted return (XIRR), which rewards adding money before the
+// price goes up. A money-weighted return above the time-weighted one means
+// the timing of the contributions helped, such as dollar-cost averaging into
+// a dip:
+//
+//	r, err := performance.Analyse(&performance.Input{
+//		Transactions: h.Transactions,
+//		Prices:       closingPrices,
+//		Rates:        sharesies.FixedRates{"usd": sharesies.MustParseDecimal("1.42")},
+//	}, performance.YearToDate(sharesies.Today()))
+//
+//	fmt.Println(r.Total.TWR, r.Total.MWR)
+package performance
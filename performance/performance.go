@@ -0,0 +1,327 @@
+package performance
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// returnPlaces is the precision returns are reported with, 0.123456 is 12.3456%
+const returnPlaces = 6
+
+var (
+	ErrInvalidPeriod  = errors.New("invalid period")
+	ErrNoTransactions = errors.New("no transactions")
+)
+
+// Prices looks up share prices to value holdings with
+type Prices interface {
+	// Price returns the closing price of one share of fundID on a day, in the fund's currency
+	Price(fundID string, on sharesies.Date) (sharesies.Decimal, error)
+}
+
+// PricesFunc is a function used as Prices
+type PricesFunc func(fundID string, on sharesies.Date) (sharesies.Decimal, error)
+
+func (f PricesFunc) Price(fundID string, on sharesies.Date) (sharesies.Decimal, error) {
+	return f(fundID, on)
+}
+
+// Input is what returns are measured from
+type Input struct {
+	// Transactions of the account, buys, sells and dividends are used
+	Transactions []*sharesies.Transaction
+	Prices       Prices
+	// Rates converts foreign holdings to NZD, may be nil if everything is in NZD
+	Rates sharesies.Rates
+}
+
+// Return is the performance of a holding, or the portfolio, over a period. Amounts are in NZD.
+type Return struct {
+	// FundID of the holding, empty for the whole portfolio
+	FundID     string
+	Period     Period
+	StartValue sharesies.Decimal
+	EndValue   sharesies.Decimal
+	// NetFlows is the money put in by buys less the money taken out by sells and dividends
+	NetFlows sharesies.Decimal
+	// Gain is EndValue less StartValue and NetFlows
+	Gain sharesies.Decimal
+	// TWR is the time-weighted return over the period as a fraction, 0.05 is 5%
+	TWR sharesies.Decimal
+	// MWR is the money-weighted return over the period as a fraction
+	MWR sharesies.Decimal
+	// AnnualisedTWR and AnnualisedMWR are the returns per year, MWR's is the XIRR
+	AnnualisedTWR sharesies.Decimal
+	AnnualisedMWR sharesies.Decimal
+	// MWRUndefined is set when no rate solves the cash flows, such as a dividend
+	// received after the holding was sold, MWR and AnnualisedMWR are zero then
+	MWRUndefined bool
+}
+
+// Report is the performance of every holding with a value or cash flow in the period, and the total
+type Report struct {
+	Total    *Return
+	Holdings []*Return
+}
+
+// change is the effect of a transaction on a holding
+type change struct {
+	day    sharesies.Date
+	shares sharesies.Shares
+	// flow is the NZD put into the holding, negative when taken out
+	flow sharesies.Decimal
+}
+
+type fund struct {
+	id       string
+	currency string
+	changes  []change
+}
+
+type analysis struct {
+	in     *Input
+	funds  []*fund
+	values map[string]map[sharesies.Date]sharesies.Decimal
+}
+
+// Analyse measures the returns of every holding and the portfolio over p
+func Analyse(in *Input, p Period) (*Report, error) {
+	a := &analysis{in: in, values: map[string]map[sharesies.Date]sharesies.Decimal{}}
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+
+	if len(a.funds) == 0 {
+		return nil, ErrNoTransactions
+	}
+
+	if p.Start.IsZero() {
+		p.Start = a.inception().AddDays(-1)
+	}
+
+	if !p.Start.Before(p.End) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidPeriod, p.Start, p.End)
+	}
+
+	r := &Report{}
+	for _, f := range a.funds {
+		ret, err := a.measure([]*fund{f}, p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.id, err)
+		}
+
+		if ret != nil {
+			ret.FundID = f.id
+			r.Holdings = append(r.Holdings, ret)
+		}
+	}
+
+	total, err := a.measure(a.funds, p)
+	if err != nil {
+		return nil, err
+	}
+
+	if total == nil {
+		total = &Return{Period: p}
+	}
+	r.Total = total
+
+	return r, nil
+}
+
+// load turns the transactions into the share and cash flow changes of each holding
+func (a *analysis) load() error {
+	byID := map[string]*fund{}
+	for _, t := range a.in.Transactions {
+		if t.FundID == "" {
+			continue
+		}
+
+		c := change{day: sharesies.NewDate(t.Timestamp.In(sharesies.NZ))}
+		switch t.Type {
+		case sharesies.TransactionTypeBuy, sharesies.TransactionTypeSell:
+			if t.Shares == nil {
+				continue
+			}
+
+			c.shares = *t.Shares
+			if t.Type == sharesies.TransactionTypeSell {
+				c.shares = sharesies.Shares{}.Sub(*t.Shares)
+			}
+		case sharesies.TransactionTypeDividend:
+		default:
+			continue
+		}
+
+		flow, err := sharesies.ToNZD(a.in.Rates, t.Amount.Neg(), t.Currency, c.day)
+		if err != nil {
+			return err
+		}
+		c.flow = flow
+
+		f, ok := byID[t.FundID]
+		if !ok {
+			f = &fund{id: t.FundID, currency: t.Currency}
+			byID[t.FundID] = f
+			a.funds = append(a.funds, f)
+		}
+		f.changes = append(f.changes, c)
+	}
+
+	sort.Slice(a.funds, func(i, j int) bool { return a.funds[i].id < a.funds[j].id })
+	for _, f := range a.funds {
+		sort.SliceStable(f.changes, func(i, j int) bool { return f.changes[i].day.Before(f.changes[j].day) })
+	}
+
+	return nil
+}
+
+func (a *analysis) inception() sharesies.Date {
+	first := a.funds[0].changes[0].day
+	for _, f := range a.funds {
+		if f.changes[0].day.Before(first) {
+			first = f.changes[0].day
+		}
+	}
+
+	return first
+}
+
+// value returns the NZD value of the shares of f held at the close of day
+func (a *analysis) value(f *fund, day sharesies.Date) (sharesies.Decimal, error) {
+	if v, ok := a.values[f.id][day]; ok {
+		return v, nil
+	}
+
+	shares := sharesies.Shares{}
+	for _, c := range f.changes {
+		if c.day.After(day) {
+			break
+		}
+		shares = shares.Add(c.shares)
+	}
+
+	v := sharesies.Decimal{}
+	if shares.Sign() > 0 {
+		price, err := a.in.Prices.Price(f.id, day)
+		if err != nil {
+			return sharesies.Decimal{}, err
+		}
+
+		v, err = sharesies.ToNZD(a.in.Rates, shares.Value(price), f.currency, day)
+		if err != nil {
+			return sharesies.Decimal{}, err
+		}
+	}
+
+	if a.values[f.id] == nil {
+		a.values[f.id] = map[sharesies.Date]sharesies.Decimal{}
+	}
+	a.values[f.id][day] = v
+
+	return v, nil
+}
+
+// measure returns the combined return of funds over p, nil when they were not held in it
+func (a *analysis) measure(funds []*fund, p Period) (*Return, error) {
+	flows := map[sharesies.Date]sharesies.Decimal{}
+	days := []sharesies.Date{p.Start}
+	for _, f := range funds {
+		for _, c := range f.changes {
+			if !c.day.After(p.Start) || c.day.After(p.End) {
+				continue
+			}
+
+			if _, ok := flows[c.day]; !ok && c.day != p.End {
+				days = append(days, c.day)
+			}
+			flows[c.day] = flows[c.day].Add(c.flow)
+		}
+	}
+	days = append(days, p.End)
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	values := make([]sharesies.Decimal, len(days))
+	for i, day := range days {
+		for _, f := range funds {
+			v, err := a.value(f, day)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = values[i].Add(v)
+		}
+	}
+
+	last := len(days) - 1
+	if values[0].IsZero() && values[last].IsZero() && len(flows) == 0 {
+		return nil, nil
+	}
+
+	r := &Return{Period: p, StartValue: values[0], EndValue: values[last]}
+
+	// chain the sub-period returns between cash flows, each flow is in the value of its day
+	growth := 1.0
+	cashflows := []CashFlow{{Date: p.Start, Amount: values[0].Neg()}}
+	for i := 1; i <= last; i++ {
+		flow := flows[days[i]]
+		r.NetFlows = r.NetFlows.Add(flow)
+		if !flow.IsZero() {
+			cashflows = append(cashflows, CashFlow{Date: days[i], Amount: flow.Neg()})
+		}
+
+		if values[i-1].Sign() <= 0 {
+			continue
+		}
+
+		// a buy bigger than the holding that closes below its price would make the
+		// sub-period negative, it is treated as made at the start of the sub-period then
+		end, start := values[i].Sub(flow), values[i-1]
+		if end.Sign() <= 0 {
+			end, start = values[i], values[i-1].Add(flow)
+		}
+		growth *= end.Float64() / start.Float64()
+	}
+	cashflows = append(cashflows, CashFlow{Date: p.End, Amount: values[last]})
+
+	r.Gain = r.EndValue.Sub(r.StartValue).Sub(r.NetFlows)
+
+	years := float64(p.Days()) / 365
+	r.TWR, _ = fraction(growth - 1)
+	if growth <= 0 {
+		r.AnnualisedTWR = sharesies.NewDecimal(-1, 0)
+	} else {
+		r.AnnualisedTWR, _ = fraction(math.Pow(growth, 1/years) - 1)
+	}
+
+	irr, err := XIRR(cashflows)
+	if err != nil {
+		if !errors.Is(err, ErrNoSolution) {
+			return nil, err
+		}
+		r.MWRUndefined = true
+		return r, nil
+	}
+
+	annualised, ok := fraction(irr)
+	mwr, okMWR := fraction(math.Pow(1+irr, years) - 1)
+	if !ok || !okMWR {
+		r.MWRUndefined = true
+		return r, nil
+	}
+	r.AnnualisedMWR, r.MWR = annualised, mwr
+
+	return r, nil
+}
+
+// fraction converts f to a Decimal, false when f is not a finite number
+func fraction(f float64) (sharesies.Decimal, bool) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return sharesies.Decimal{}, false
+	}
+
+	return sharesies.NewDecimalFromFloat(f).Round(returnPlaces), true
+}
@@ -0,0 +1,207 @@
+package performance_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/performance"
+	"github.com/deividfortuna/sharesies/tax"
+)
+
+const (
+	nzFund = "nz-fund"
+	usFund = "us-fund"
+)
+
+func decimal(s string) sharesies.Decimal {
+	return sharesies.MustParseDecimal(s)
+}
+
+func date(year int, month time.Month, day int) sharesies.Date {
+	return sharesies.Date{Year: year, Month: month, Day: day}
+}
+
+func trade(typ string, on sharesies.Date, fundID, currency, amount, shares string) *sharesies.Transaction {
+	t := &sharesies.Transaction{Type: typ, Timestamp: on.In(time.UTC), FundID: fundID, Currency: currency, Amount: decimal(amount)}
+	if shares != "" {
+		s := sharesies.MustParseShares(shares)
+		t.Shares = &s
+	}
+
+	return t
+}
+
+// prices returns the price of each fund from the latest date on or before the day
+func prices(history map[string]map[sharesies.Date]string) performance.Prices {
+	return performance.PricesFunc(func(fundID string, on sharesies.Date) (sharesies.Decimal, error) {
+		var latest sharesies.Date
+		for d := range history[fundID] {
+			if !d.After(on) && d.After(latest) {
+				latest = d
+			}
+		}
+
+		return decimal(history[fundID][latest]), nil
+	})
+}
+
+func input() *performance.Input {
+	return &performance.Input{
+		Transactions: []*sharesies.Transaction{
+			trade(sharesies.TransactionTypeBuy, date(2022, time.January, 1), nzFund, "nzd", "-100", "100"),
+			trade(sharesies.TransactionTypeBuy, date(2022, time.January, 31), nzFund, "nzd", "-200", "100"),
+			trade(sharesies.TransactionTypeBuy, date(2022, time.January, 1), usFund, "usd", "-100", "10"),
+			trade(sharesies.TransactionTypeDividend, date(2022, time.February, 10), usFund, "usd", "10", ""),
+			trade(sharesies.TransactionTypeDeposit, date(2022, time.January, 1), "", "nzd", "1000", ""),
+		},
+		Prices: prices(map[string]map[sharesies.Date]string{
+			nzFund: {date(2022, time.January, 1): "1", date(2022, time.January, 31): "2", date(2022, time.February, 28): "1.5"},
+			usFund: {date(2022, time.January, 1): "10", date(2022, time.February, 28): "11"},
+		}),
+		Rates: sharesies.FixedRates{"usd": decimal("1.5")},
+	}
+}
+
+func Test_Analyse_SinceInception(t *testing.T) {
+	r, err := performance.Analyse(input(), performance.SinceInception(date(2022, time.February, 28)))
+
+	assert.Nil(t, err)
+	assert.Len(t, r.Holdings, 2)
+
+	nz := r.Holdings[0]
+	assert.Equal(t, nzFund, nz.FundID)
+	assert.Equal(t, date(2021, time.December, 31), nz.Period.Start)
+	assert.True(t, decimal("300").Equal(nz.NetFlows), nz.NetFlows.String())
+	assert.True(t, decimal("300").Equal(nz.EndValue), nz.EndValue.String())
+	assert.True(t, nz.Gain.IsZero())
+	assert.True(t, decimal("0.5").Equal(nz.TWR), nz.TWR.String())
+	assert.True(t, nz.MWR.IsZero(), nz.MWR.String())
+
+	us := r.Holdings[1]
+	assert.True(t, decimal("165").Equal(us.EndValue), us.EndValue.String())
+	assert.True(t, decimal("135").Equal(us.NetFlows), us.NetFlows.String())
+	assert.True(t, decimal("30").Equal(us.Gain), us.Gain.String())
+	assert.True(t, decimal("0.21").Equal(us.TWR), us.TWR.String())
+	assert.InDelta(t, 0.21, us.MWR.Float64(), 0.001)
+
+	assert.True(t, decimal("465").Equal(r.Total.EndValue))
+	assert.True(t, decimal("30").Equal(r.Total.Gain))
+	assert.True(t, r.Total.AnnualisedTWR.GreaterThan(r.Total.TWR))
+}
+
+func Test_Analyse_Period(t *testing.T) {
+	r, err := performance.Analyse(input(), performance.Period{Start: date(2022, time.January, 31), End: date(2022, time.February, 28)})
+
+	assert.Nil(t, err)
+
+	nz := r.Holdings[0]
+	assert.True(t, decimal("400").Equal(nz.StartValue), nz.StartValue.String())
+	assert.True(t, nz.NetFlows.IsZero())
+	assert.True(t, decimal("-0.25").Equal(nz.TWR), nz.TWR.String())
+	assert.True(t, decimal("-0.25").Equal(nz.MWR), nz.MWR.String())
+}
+
+func Test_Analyse_BuyClosingBelowPrice(t *testing.T) {
+	in := &performance.Input{
+		Transactions: []*sharesies.Transaction{
+			trade(sharesies.TransactionTypeBuy, date(2022, time.January, 1), nzFund, "nzd", "-10", "10"),
+			trade(sharesies.TransactionTypeBuy, date(2022, time.February, 1), nzFund, "nzd", "-1000", "1000"),
+		},
+		Prices: prices(map[string]map[sharesies.Date]string{
+			nzFund: {date(2022, time.January, 1): "1", date(2022, time.February, 1): "0.98"},
+		}),
+	}
+
+	r, err := performance.Analyse(in, performance.SinceInception(date(2022, time.February, 28)))
+
+	assert.Nil(t, err)
+	assert.True(t, decimal("-0.02").Equal(r.Total.TWR), r.Total.TWR.String())
+	assert.True(t, r.Total.AnnualisedTWR.Sign() < 0, r.Total.AnnualisedTWR.String())
+	assert.False(t, r.Total.MWRUndefined)
+}
+
+func Test_Analyse_DividendAfterSale(t *testing.T) {
+	in := &performance.Input{
+		Transactions: []*sharesies.Transaction{
+			trade(sharesies.TransactionTypeBuy, date(2022, time.January, 1), nzFund, "nzd", "-100", "100"),
+			trade(sharesies.TransactionTypeSell, date(2022, time.January, 31), nzFund, "nzd", "150", "100"),
+			trade(sharesies.TransactionTypeDividend, date(2022, time.February, 10), nzFund, "nzd", "5", ""),
+		},
+		Prices: prices(map[string]map[sharesies.Date]string{
+			nzFund: {date(2022, time.January, 1): "1", date(2022, time.January, 31): "1.5"},
+		}),
+	}
+
+	r, err := performance.Analyse(in, performance.Period{Start: date(2022, time.February, 1), End: date(2022, time.February, 28)})
+
+	assert.Nil(t, err)
+	assert.Len(t, r.Holdings, 1)
+	assert.True(t, r.Holdings[0].MWRUndefined)
+	assert.True(t, r.Holdings[0].MWR.IsZero())
+	assert.True(t, decimal("-5").Equal(r.Holdings[0].NetFlows), r.Holdings[0].NetFlows.String())
+}
+
+func Test_Analyse_NZDate(t *testing.T) {
+	buy := trade(sharesies.TransactionTypeBuy, date(2021, time.April, 1), nzFund, "nzd", "-100", "100")
+	// 09:00 NZDT on 1 April, the first day of the 2022 tax year, is still 31 March in UTC
+	buy.Timestamp = time.Date(2021, time.March, 31, 20, 0, 0, 0, time.UTC)
+
+	in := &performance.Input{
+		Transactions: []*sharesies.Transaction{buy},
+		Prices:       prices(map[string]map[sharesies.Date]string{nzFund: {date(2021, time.April, 1): "1"}}),
+	}
+
+	r, err := performance.Analyse(in, performance.TaxYear(tax.TaxYear(2022)))
+
+	assert.Nil(t, err)
+	assert.True(t, r.Total.StartValue.IsZero(), r.Total.StartValue.String())
+	assert.True(t, decimal("100").Equal(r.Total.NetFlows), r.Total.NetFlows.String())
+}
+
+func Test_Analyse_Errors(t *testing.T) {
+	_, err := performance.Analyse(&performance.Input{}, performance.SinceInception(date(2022, time.March, 1)))
+	assert.Equal(t, performance.ErrNoTransactions, err)
+
+	_, err = performance.Analyse(input(), performance.Period{Start: date(2022, time.March, 1), End: date(2022, time.March, 1)})
+	assert.True(t, errors.Is(err, performance.ErrInvalidPeriod))
+
+	in := input()
+	in.Rates = nil
+	_, err = performance.Analyse(in, performance.SinceInception(date(2022, time.March, 1)))
+	assert.True(t, errors.Is(err, sharesies.ErrMissingRate))
+}
+
+func Test_XIRR(t *testing.T) {
+	r, err := performance.XIRR([]performance.CashFlow{
+		{Date: date(2021, time.January, 1), Amount: decimal("-1000")},
+		{Date: date(2022, time.January, 1), Amount: decimal("1100")},
+	})
+	assert.Nil(t, err)
+	assert.InDelta(t, 0.1, r, 1e-9)
+
+	r, err = performance.XIRR([]performance.CashFlow{
+		{Date: date(2021, time.January, 1), Amount: decimal("-1000")},
+		{Date: date(2021, time.July, 1), Amount: decimal("-1000")},
+		{Date: date(2022, time.January, 1), Amount: decimal("1500")},
+	})
+	assert.Nil(t, err)
+	assert.True(t, r < -0.3 && !math.IsNaN(r), r)
+
+	_, err = performance.XIRR([]performance.CashFlow{{Date: date(2021, time.January, 1), Amount: decimal("1000")}})
+	assert.Equal(t, performance.ErrNoSolution, err)
+}
+
+func Test_Periods(t *testing.T) {
+	end := date(2022, time.March, 31)
+
+	assert.Equal(t, date(2022, time.February, 28), performance.Months(1, end).Start)
+	assert.Equal(t, date(2021, time.December, 31), performance.Months(3, end).Start)
+	assert.Equal(t, date(2021, time.December, 31), performance.YearToDate(end).Start)
+	assert.Equal(t, performance.Period{Start: date(2021, time.March, 31), End: end}, performance.TaxYear(tax.TaxYear(2022)))
+	assert.Equal(t, 31, performance.Months(1, end).Days())
+}
@@ -0,0 +1,49 @@
+package performance
+
+import (
+	"time"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/tax"
+)
+
+// Period measures returns from the close of Start to the close of End, a
+// zero Start runs from the day before the first transaction
+type Period struct {
+	Start sharesies.Date
+	End   sharesies.Date
+}
+
+// Months returns the n months ending on end, Months(3, end) is the 3m period.
+// The start is clamped to the end of shorter months, 31 March goes back to 28 February.
+func Months(n int, end sharesies.Date) Period {
+	first := time.Date(end.Year, end.Month-time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	start := sharesies.NewDate(first)
+	if last := first.AddDate(0, 1, -1).Day(); end.Day > last {
+		start.Day = last
+	} else {
+		start.Day = end.Day
+	}
+
+	return Period{Start: start, End: end}
+}
+
+// YearToDate returns the period from the end of last year to end
+func YearToDate(end sharesies.Date) Period {
+	return Period{Start: sharesies.Date{Year: end.Year - 1, Month: time.December, Day: 31}, End: end}
+}
+
+// TaxYear returns the New Zealand tax year y, 1 April to 31 March
+func TaxYear(y tax.TaxYear) Period {
+	return Period{Start: y.Start().AddDays(-1), End: y.End()}
+}
+
+// SinceInception returns the period from the first transaction to end
+func SinceInception(end sharesies.Date) Period {
+	return Period{End: end}
+}
+
+// Days returns the length of the period in days
+func (p Period) Days() int {
+	return int(p.End.In(time.UTC).Sub(p.Start.In(time.UTC)).Hours() / 24)
+}
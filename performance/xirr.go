@@ -0,0 +1,118 @@
+package performance
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+var ErrNoSolution = errors.New("no internal rate of return")
+
+const (
+	xirrTolerance  = 1e-10
+	xirrIterations = 100
+)
+
+// CashFlow is money paid in, negative, or received, positive, on a day
+type CashFlow struct {
+	Date   sharesies.Date
+	Amount sharesies.Decimal
+}
+
+// XIRR returns the annual rate of return that discounts flows to zero, the
+// final value of an investment is a positive flow on the last day
+func XIRR(flows []CashFlow) (float64, error) {
+	if len(flows) == 0 {
+		return 0, ErrNoSolution
+	}
+
+	first := flows[0].Date.In(time.UTC)
+	years := make([]float64, len(flows))
+	amounts := make([]float64, len(flows))
+	paid, received := false, false
+	for i, f := range flows {
+		if d := f.Date.In(time.UTC); d.Before(first) {
+			first = d
+		}
+
+		amounts[i] = f.Amount.Float64()
+		paid = paid || amounts[i] < 0
+		received = received || amounts[i] > 0
+	}
+
+	if !paid || !received {
+		if paid {
+			// nothing came back, everything was lost
+			return -1, nil
+		}
+
+		return 0, ErrNoSolution
+	}
+
+	for i, f := range flows {
+		years[i] = f.Date.In(time.UTC).Sub(first).Hours() / 24 / 365
+	}
+
+	npv := func(r float64) (float64, float64) {
+		v, dv := 0.0, 0.0
+		for i, a := range amounts {
+			v += a / math.Pow(1+r, years[i])
+			dv -= years[i] * a / math.Pow(1+r, years[i]+1)
+		}
+		return v, dv
+	}
+
+	r := 0.1
+	for i := 0; i < xirrIterations; i++ {
+		v, dv := npv(r)
+		if math.Abs(v) < xirrTolerance {
+			return r, nil
+		}
+		if dv == 0 {
+			break
+		}
+
+		next := r - v/dv
+		if next <= -1 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		if math.Abs(next-r) < xirrTolerance {
+			return next, nil
+		}
+		r = next
+	}
+
+	return bisect(npv)
+}
+
+// bisect finds the rate Newton's method could not, between -100% and a large gain
+func bisect(npv func(float64) (float64, float64)) (float64, error) {
+	lo, hi := -0.999999, 1.0
+	vlo, _ := npv(lo)
+	vhi, _ := npv(hi)
+	for vlo*vhi > 0 {
+		if hi > 1e6 {
+			return 0, ErrNoSolution
+		}
+		hi *= 10
+		vhi, _ = npv(hi)
+	}
+
+	for i := 0; i < 200; i++ {
+		mid := (lo + hi) / 2
+		v, _ := npv(mid)
+		if math.Abs(v) < xirrTolerance || hi-lo < xirrTolerance {
+			return mid, nil
+		}
+
+		if v*vlo > 0 {
+			lo, vlo = mid, v
+		} else {
+			hi = mid
+		}
+	}
+
+	return (lo + hi) / 2, nil
+}
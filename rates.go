@@ -0,0 +1,61 @@
+package sharesies
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrMissingRate = errors.New("missing exchange rate")
+
+// Rates converts foreign currency amounts to New Zealand dollars
+type Rates interface {
+	// Rate returns how many NZD one unit of currency, such as "usd", was worth on a day
+	Rate(currency string, on Date) (Decimal, error)
+}
+
+// FixedRates is a Rates using the same rate all year, such as the IRD
+// published mid-month average or end of year rates
+type FixedRates map[string]Decimal
+
+func (r FixedRates) Rate(currency string, _ Date) (Decimal, error) {
+	currency = strings.ToLower(currency)
+	if currency == "nzd" {
+		return NewDecimal(1, 0), nil
+	}
+
+	rate, ok := r[currency]
+	if !ok {
+		return Decimal{}, fmt.Errorf("%w: %s", ErrMissingRate, currency)
+	}
+
+	return rate, nil
+}
+
+// RateToNZD returns how many NZD one unit of currency was worth on a day, 1
+// for NZD or no currency. rates may be nil when only NZD amounts are converted.
+func RateToNZD(rates Rates, currency string, on Date) (Decimal, error) {
+	if currency == "" || strings.EqualFold(currency, "nzd") {
+		return NewDecimal(1, 0), nil
+	}
+
+	if rates == nil {
+		return Decimal{}, fmt.Errorf("%w: %s", ErrMissingRate, currency)
+	}
+
+	return rates.Rate(strings.ToLower(currency), on)
+}
+
+// ToNZD converts amount in currency on a day to NZD, see RateToNZD
+func ToNZD(rates Rates, amount Decimal, currency string, on Date) (Decimal, error) {
+	if currency == "" || strings.EqualFold(currency, "nzd") {
+		return amount, nil
+	}
+
+	rate, err := RateToNZD(rates, currency, on)
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	return amount.Mul(rate), nil
+}
@@ -0,0 +1,29 @@
+package sharesies_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+func Test_ToNZD(t *testing.T) {
+	on := sharesies.Date{Year: 2022, Month: time.January, Day: 1}
+	ten := sharesies.NewDecimal(10, 0)
+
+	nzd, err := sharesies.ToNZD(nil, ten, "NZD", on)
+	assert.Nil(t, err)
+	assert.True(t, ten.Equal(nzd))
+
+	usd, err := sharesies.ToNZD(sharesies.FixedRates{"usd": sharesies.MustParseDecimal("1.5")}, ten, "USD", on)
+	assert.Nil(t, err)
+	assert.True(t, sharesies.NewDecimal(15, 0).Equal(usd))
+
+	_, err = sharesies.RateToNZD(nil, "aud", on)
+	assert.ErrorIs(t, err, sharesies.ErrMissingRate)
+
+	_, err = sharesies.FixedRates{}.Rate("aud", on)
+	assert.ErrorIs(t, err, sharesies.ErrMissingRate)
+}
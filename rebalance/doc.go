@@ -0,0 +1,24 @@
+// Package rebalance computes and places the trades that bring a portfolio
+// back to its target weights.
+//
+// Holdings drifting from their target by more than the tolerance band are
+// sold or bought back to target, with new cash going to the most underweight
+// holdings first. In buy-only mode nothing is sold and only the cash is
+// invested:
+//
+//	r := &rebalance.Rebalancer{
+//		Client: s,
+//		Targets: rebalance.Targets{
+//			nz50:  sharesies.NewDecimal(60, 0),
+//			sp500: sharesies.NewDecimal(40, 0),
+//		},
+//		Options: &rebalance.Options{Tolerance: sharesies.NewDecimal(5, 0)},
+//	}
+//
+//	plan, err := r.Plan(ctx)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	err = r.Execute(ctx, plan)
+package rebalance
@@ -0,0 +1,352 @@
+package rebalance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+var (
+	ErrInvalidTargets = errors.New("target weights must be positive and add up to 100")
+	ErrNoPrice        = errors.New("no price for holding")
+	ErrNoInstrument   = errors.New("no instrument for target not held")
+)
+
+var hundred = sharesies.NewDecimal(100, 0)
+
+// Targets maps fund IDs to their target weight in percent, adding up to 100
+type Targets map[string]sharesies.Decimal
+
+// Validate checks every weight is positive and they add up to 100
+func (t Targets) Validate() error {
+	total := sharesies.Decimal{}
+	for _, w := range t {
+		if w.Sign() <= 0 {
+			return ErrInvalidTargets
+		}
+		total = total.Add(w)
+	}
+
+	if !total.Equal(hundred) {
+		return fmt.Errorf("%w: they add up to %s", ErrInvalidTargets, total)
+	}
+
+	return nil
+}
+
+// Options tune a rebalance
+type Options struct {
+	// Tolerance is how many percentage points a holding may drift from its target before it is traded
+	Tolerance sharesies.Decimal
+	// Cash is the NZD available to invest on top of the holdings, such as the
+	// wallet balance. It buys funds in any currency, exchanged on the way.
+	Cash sharesies.Decimal
+	// ForeignCash is the balance of the other wallets by currency, such as "usd",
+	// each only buys funds trading in its currency
+	ForeignCash map[string]sharesies.Decimal
+	// Instruments of the targets not held yet, their currency is sharesies.Company.Currency
+	Instruments map[string]*sharesies.Company
+	// BuyOnly never sells, Cash is invested in the underweight holdings
+	BuyOnly bool
+	// MinTrade skips trades worth less, defaults to sharesies.MinimumLimitOrderValue
+	MinTrade sharesies.Decimal
+	// SellFeeRate estimates the fee on sale proceeds, sell quotes do not include one
+	SellFeeRate sharesies.Decimal
+	// Rates converts foreign holdings to NZD, may be nil if every holding is in NZD
+	Rates sharesies.Rates
+	// On is the day foreign holdings are converted on, defaults to today in New Zealand
+	On sharesies.Date
+}
+
+// Trade is an order that moves a holding toward its target
+type Trade struct {
+	FundID string
+	// Side is sharesies.OrderSideBuy or sharesies.OrderSideSell
+	Side     string
+	Currency string
+	// Amount is the value traded in Currency, the quoted cost of a buy may add the fee on top
+	Amount sharesies.Decimal
+	// Shares sold, set for sells only
+	Shares sharesies.Shares
+	// Fee is the quoted fee for buys and the estimated fee for sells
+	Fee sharesies.Decimal
+	// Current and Target are the weights of the holding in percent before trading
+	Current sharesies.Decimal
+	Target  sharesies.Decimal
+	// OrderID is the order placed for the trade, empty when it could not be
+	// told apart, see sharesies.ProfileResponse.PlacedOrder
+	OrderID string
+
+	placed bool
+	rate   sharesies.Decimal
+	buy    *sharesies.CostBuyResponse
+	sell   *sharesies.CostSellResponse
+}
+
+// Plan is the set of trades of a rebalance, sells first
+type Plan struct {
+	// Value is the NZD value of the holdings and cash
+	Value  sharesies.Decimal
+	Trades []*Trade
+	// Cash is available to buys by currency, the Options cash and the sale
+	// proceeds less fees, which stay in the wallet of the fund's currency
+	Cash map[string]sharesies.Decimal
+}
+
+// Fees returns the fees of every trade in their currencies
+func (p *Plan) Fees() map[string]sharesies.Decimal {
+	fees := map[string]sharesies.Decimal{}
+	for _, t := range p.Trades {
+		fees[t.Currency] = fees[t.Currency].Add(t.Fee)
+	}
+
+	return fees
+}
+
+// holding is a fund held or targeted, with values in NZD
+type holding struct {
+	fundID   string
+	currency string
+	shares   sharesies.Shares
+	value    sharesies.Decimal
+	// rate converts the fund's currency to NZD
+	rate    sharesies.Decimal
+	current sharesies.Decimal
+	target  sharesies.Decimal
+}
+
+// Compute returns the trades rebalancing the portfolio of p toward targets,
+// without fees. Use Rebalancer to quote fees and place the trades.
+func Compute(p *sharesies.ProfileResponse, targets Targets, opts *Options) (*Plan, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	if err := targets.Validate(); err != nil {
+		return nil, err
+	}
+
+	holdings, cash, total, err := load(p, targets, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	min := opts.MinTrade
+	if min.IsZero() {
+		min = sharesies.MinimumLimitOrderValue
+	}
+
+	plan := &Plan{Value: total, Cash: map[string]sharesies.Decimal{}}
+	if !opts.Cash.IsZero() {
+		plan.Cash["nzd"] = opts.Cash
+	}
+	for currency, amount := range opts.ForeignCash {
+		currency = strings.ToLower(currency)
+		plan.Cash[currency] = plan.Cash[currency].Add(amount)
+	}
+
+	if total.IsZero() {
+		return plan, nil
+	}
+
+	drifted := func(h *holding) bool {
+		return h.current.Sub(h.target).Abs().GreaterThan(opts.Tolerance)
+	}
+
+	// sell overweight holdings outside the band back to target
+	if !opts.BuyOnly {
+		for _, h := range holdings {
+			if !drifted(h) || h.current.LessThan(h.target) {
+				continue
+			}
+
+			excess := h.value.Sub(total.Mul(h.target).Div(hundred, 2))
+			t := &Trade{FundID: h.fundID, Side: sharesies.OrderSideSell, Currency: h.currency, Current: h.current, Target: h.target, rate: h.rate}
+			if h.target.IsZero() {
+				t.Shares = h.shares
+				excess = h.value
+			} else {
+				t.Shares = sharesies.Shares(h.shares.Decimal().Mul(excess).Div(h.value, sharesies.SharesPlaces+4).Truncate(sharesies.SharesPlaces))
+			}
+
+			if excess.LessThan(min) || t.Shares.Sign() <= 0 {
+				continue
+			}
+
+			t.Amount = excess.Div(h.rate, 2)
+			t.Fee = t.Amount.Mul(opts.SellFeeRate).Round(2)
+			cash[h.currency] = cash[h.currency].Add(t.Amount.Sub(t.Fee).Mul(h.rate))
+			plan.Cash[h.currency] = plan.Cash[h.currency].Add(t.Amount.Sub(t.Fee))
+			plan.Trades = append(plan.Trades, t)
+		}
+	}
+
+	// buy underweight holdings, those outside the band first
+	for _, outside := range []bool{true, false} {
+		deficits := map[*holding]sharesies.Decimal{}
+		for _, h := range holdings {
+			if h.target.IsZero() || drifted(h) != outside || !h.current.LessThan(h.target) {
+				continue
+			}
+
+			deficits[h] = total.Mul(h.target).Div(hundred, 2).Sub(h.value)
+		}
+
+		// foreign wallets pay for the funds in their currency first, NZD for the rest
+		paid := map[*holding]map[string]sharesies.Decimal{}
+		for _, currency := range payingOrder(cash) {
+			share(cash[currency], currency, holdings, deficits, paid)
+		}
+
+		for _, h := range holdings {
+			amount := sharesies.Decimal{}
+			for _, a := range paid[h] {
+				amount = amount.Add(a)
+			}
+
+			if amount.Sign() <= 0 || amount.LessThan(min) {
+				continue
+			}
+
+			for currency, a := range paid[h] {
+				cash[currency] = cash[currency].Sub(a)
+			}
+
+			plan.Trades = append(plan.Trades, &Trade{
+				FundID:   h.fundID,
+				Side:     sharesies.OrderSideBuy,
+				Currency: h.currency,
+				Amount:   amount.Div(h.rate, 2),
+				Current:  h.current,
+				Target:   h.target,
+				rate:     h.rate,
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+// payingOrder returns the currencies of cash, foreign ones first and NZD last
+func payingOrder(cash map[string]sharesies.Decimal) []string {
+	currencies := []string{}
+	for currency := range cash {
+		if currency != "nzd" {
+			currencies = append(currencies, currency)
+		}
+	}
+	sort.Strings(currencies)
+
+	return append(currencies, "nzd")
+}
+
+// share splits what the wallet of currency can pay, in NZD, across the
+// deficits left in proportion. NZD pays for any fund, other currencies only
+// for the funds trading in them.
+func share(available sharesies.Decimal, currency string, holdings []*holding, deficits map[*holding]sharesies.Decimal, paid map[*holding]map[string]sharesies.Decimal) {
+	left := map[*holding]sharesies.Decimal{}
+	needed := sharesies.Decimal{}
+	for _, h := range holdings {
+		d, ok := deficits[h]
+		if !ok || (currency != "nzd" && h.currency != currency) {
+			continue
+		}
+
+		for _, a := range paid[h] {
+			d = d.Sub(a)
+		}
+
+		if d.Sign() > 0 {
+			left[h] = d
+			needed = needed.Add(d)
+		}
+	}
+
+	if needed.Sign() <= 0 || available.Sign() <= 0 {
+		return
+	}
+
+	spend := sharesies.MinDecimal(available, needed)
+	for _, h := range holdings {
+		d, ok := left[h]
+		if !ok {
+			continue
+		}
+
+		if paid[h] == nil {
+			paid[h] = map[string]sharesies.Decimal{}
+		}
+		paid[h][currency] = paid[h][currency].Add(spend.Mul(d).Div(needed, 4).Truncate(2))
+	}
+}
+
+// load returns every fund held or targeted with its NZD value and weight,
+// sorted by fund, and the NZD value of the cash in each wallet
+func load(p *sharesies.ProfileResponse, targets Targets, opts *Options) ([]*holding, map[string]sharesies.Decimal, sharesies.Decimal, error) {
+	on := opts.On
+	if on.IsZero() {
+		on = sharesies.Today()
+	}
+
+	cash := map[string]sharesies.Decimal{"nzd": opts.Cash}
+	total := opts.Cash
+	for currency, amount := range opts.ForeignCash {
+		value, err := sharesies.ToNZD(opts.Rates, amount, currency, on)
+		if err != nil {
+			return nil, nil, sharesies.Decimal{}, err
+		}
+
+		currency = strings.ToLower(currency)
+		cash[currency] = cash[currency].Add(value)
+		total = total.Add(value)
+	}
+
+	byID := map[string]*holding{}
+	for _, pf := range p.Portfolio {
+		rate, err := sharesies.RateToNZD(opts.Rates, pf.Currency, on)
+		if err != nil {
+			return nil, nil, sharesies.Decimal{}, err
+		}
+
+		h := &holding{fundID: pf.FundID, currency: strings.ToLower(pf.Currency), shares: pf.Shares, value: pf.Value.Mul(rate), rate: rate}
+		if h.shares.Sign() > 0 && h.value.Sign() <= 0 {
+			return nil, nil, sharesies.Decimal{}, fmt.Errorf("%w: %s", ErrNoPrice, pf.FundID)
+		}
+
+		byID[pf.FundID] = h
+		total = total.Add(h.value)
+	}
+
+	for fundID, w := range targets {
+		h, ok := byID[fundID]
+		if !ok {
+			c, ok := opts.Instruments[fundID]
+			if !ok {
+				return nil, nil, sharesies.Decimal{}, fmt.Errorf("%w: %s", ErrNoInstrument, fundID)
+			}
+
+			rate, err := sharesies.RateToNZD(opts.Rates, c.Currency(), on)
+			if err != nil {
+				return nil, nil, sharesies.Decimal{}, err
+			}
+
+			h = &holding{fundID: fundID, currency: c.Currency(), rate: rate}
+			byID[fundID] = h
+		}
+		h.target = w
+	}
+
+	holdings := make([]*holding, 0, len(byID))
+	for _, h := range byID {
+		if total.Sign() > 0 {
+			h.current = h.value.Mul(hundred).Div(total, 4)
+		}
+		holdings = append(holdings, h)
+	}
+	sort.Slice(holdings, func(i, j int) bool { return holdings[i].fundID < holdings[j].fundID })
+
+	return holdings, cash, total, nil
+}
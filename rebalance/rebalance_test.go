@@ -0,0 +1,245 @@
+package rebalance_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/rebalance"
+	"github.com/deividfortuna/sharesies/sharesiestest"
+)
+
+const (
+	fundA = "a-fund"
+	fundB = "b-fund"
+	fundC = "c-fund"
+)
+
+func decimal(s string) sharesies.Decimal {
+	return sharesies.MustParseDecimal(s)
+}
+
+func profile(holdings ...*sharesies.Portfolio) *sharesies.ProfileResponse {
+	return &sharesies.ProfileResponse{Portfolio: holdings}
+}
+
+func holding(fundID, currency, shares, value string) *sharesies.Portfolio {
+	return &sharesies.Portfolio{FundID: fundID, Currency: currency, Shares: sharesies.MustParseShares(shares), Value: decimal(value)}
+}
+
+func half() rebalance.Targets {
+	return rebalance.Targets{fundA: decimal("50"), fundB: decimal("50")}
+}
+
+func Test_Compute(t *testing.T) {
+	plan, err := rebalance.Compute(
+		profile(holding(fundA, "nzd", "350", "700"), holding(fundB, "nzd", "60", "300")),
+		half(),
+		&rebalance.Options{Tolerance: decimal("5")},
+	)
+
+	assert.Nil(t, err)
+	assert.True(t, decimal("1000").Equal(plan.Value))
+	assert.Len(t, plan.Trades, 2)
+
+	sell := plan.Trades[0]
+	assert.Equal(t, fundA, sell.FundID)
+	assert.Equal(t, sharesies.OrderSideSell, sell.Side)
+	assert.True(t, decimal("200").Equal(sell.Amount), sell.Amount.String())
+	assert.Equal(t, "100", sell.Shares.Round(0).String())
+	assert.True(t, decimal("70").Equal(sell.Current))
+
+	buy := plan.Trades[1]
+	assert.Equal(t, fundB, buy.FundID)
+	assert.Equal(t, sharesies.OrderSideBuy, buy.Side)
+	assert.True(t, decimal("200").Equal(buy.Amount), buy.Amount.String())
+}
+
+func Test_Compute_WithinBand(t *testing.T) {
+	plan, err := rebalance.Compute(
+		profile(holding(fundA, "nzd", "260", "520"), holding(fundB, "nzd", "96", "480")),
+		half(),
+		&rebalance.Options{Tolerance: decimal("5")},
+	)
+
+	assert.Nil(t, err)
+	assert.Empty(t, plan.Trades)
+}
+
+func Test_Compute_BuyOnly(t *testing.T) {
+	plan, err := rebalance.Compute(
+		profile(holding(fundA, "nzd", "350", "700"), holding(fundB, "nzd", "60", "300")),
+		half(),
+		&rebalance.Options{Tolerance: decimal("5"), BuyOnly: true, Cash: decimal("100")},
+	)
+
+	assert.Nil(t, err)
+	assert.Len(t, plan.Trades, 1)
+	assert.Equal(t, fundB, plan.Trades[0].FundID)
+	assert.True(t, decimal("100").Equal(plan.Trades[0].Amount))
+}
+
+func Test_Compute_CashToUnderweightFirst(t *testing.T) {
+	targets := rebalance.Targets{fundA: decimal("50"), fundB: decimal("30"), fundC: decimal("20")}
+
+	plan, err := rebalance.Compute(
+		profile(holding(fundA, "nzd", "250", "500"), holding(fundB, "nzd", "50", "250"), holding(fundC, "nzd", "10", "150")),
+		targets,
+		&rebalance.Options{Tolerance: decimal("3"), BuyOnly: true, Cash: decimal("100")},
+	)
+
+	// B is 25% against 30%, outside the band, C is 15% against 20% too but A is on target
+	assert.Nil(t, err)
+	assert.Len(t, plan.Trades, 2)
+	assert.Equal(t, fundB, plan.Trades[0].FundID)
+	assert.True(t, decimal("50").Equal(plan.Trades[0].Amount), plan.Trades[0].Amount.String())
+	assert.Equal(t, fundC, plan.Trades[1].FundID)
+	assert.True(t, decimal("50").Equal(plan.Trades[1].Amount), plan.Trades[1].Amount.String())
+}
+
+func Test_Compute_SellsUntargeted(t *testing.T) {
+	plan, err := rebalance.Compute(
+		profile(holding(fundA, "nzd", "250", "500"), holding(fundB, "nzd", "100", "450"), holding(fundC, "usd", "3", "50")),
+		half(),
+		&rebalance.Options{Tolerance: decimal("5"), Rates: sharesies.FixedRates{"usd": decimal("1.5")}},
+	)
+
+	// the proceeds stay in the USD wallet, they cannot buy the NZD funds
+	assert.Nil(t, err)
+	assert.Len(t, plan.Trades, 1)
+	assert.Equal(t, fundC, plan.Trades[0].FundID)
+	assert.Equal(t, "usd", plan.Trades[0].Currency)
+	assert.Equal(t, "3", plan.Trades[0].Shares.String())
+	assert.True(t, decimal("50").Equal(plan.Trades[0].Amount))
+	assert.True(t, decimal("50").Equal(plan.Cash["usd"]), plan.Cash["usd"].String())
+	assert.True(t, plan.Cash["nzd"].IsZero())
+}
+
+func Test_Compute_ForeignTarget(t *testing.T) {
+	plan, err := rebalance.Compute(
+		profile(holding(fundA, "nzd", "150", "300")),
+		half(),
+		&rebalance.Options{
+			Tolerance:   decimal("5"),
+			ForeignCash: map[string]sharesies.Decimal{"usd": decimal("100")},
+			Instruments: map[string]*sharesies.Company{fundB: {ID: fundB, Exchangecountry: "usa"}},
+			Rates:       sharesies.FixedRates{"usd": decimal("1.5")},
+		},
+	)
+
+	// 450 NZD in total, B is bought with the 100 USD and the 75 NZD A is sold for
+	assert.Nil(t, err)
+	assert.True(t, decimal("450").Equal(plan.Value), plan.Value.String())
+	assert.Len(t, plan.Trades, 2)
+	assert.Equal(t, sharesies.OrderSideSell, plan.Trades[0].Side)
+	assert.True(t, decimal("75").Equal(plan.Trades[0].Amount), plan.Trades[0].Amount.String())
+
+	buy := plan.Trades[1]
+	assert.Equal(t, fundB, buy.FundID)
+	assert.Equal(t, "usd", buy.Currency)
+	assert.True(t, decimal("150").Equal(buy.Amount), buy.Amount.String())
+	assert.True(t, decimal("100").Equal(plan.Cash["usd"]))
+	assert.True(t, decimal("75").Equal(plan.Cash["nzd"]))
+}
+
+func Test_Compute_Errors(t *testing.T) {
+	_, err := rebalance.Compute(profile(), rebalance.Targets{fundA: decimal("60")}, nil)
+	assert.True(t, errors.Is(err, rebalance.ErrInvalidTargets))
+
+	_, err = rebalance.Compute(profile(holding(fundC, "usd", "3", "50")), half(), nil)
+	assert.True(t, errors.Is(err, sharesies.ErrMissingRate))
+
+	_, err = rebalance.Compute(profile(holding(fundA, "nzd", "1", "2")), half(), nil)
+	assert.True(t, errors.Is(err, rebalance.ErrNoInstrument))
+}
+
+func Test_Rebalancer(t *testing.T) {
+	srv := sharesiestest.NewServer()
+	defer srv.Close()
+
+	srv.AddInstrument(&sharesies.Company{ID: fundA, Symbol: "AAA", Marketprice: decimal("2.00"), Exchange: "NZX", Exchangecountry: "nzl"})
+	srv.AddInstrument(&sharesies.Company{ID: fundB, Symbol: "BBB", Marketprice: decimal("5.00"), Exchange: "NZX", Exchangecountry: "nzl"})
+
+	acc := srv.AddUser("username", "password")
+	acc.Deposit("nzd", sharesies.NewDecimal(1000, 0))
+
+	ctx := context.Background()
+	s, _ := sharesies.NewWithOptions(srv.Options())
+	_, err := s.Authenticate(ctx, &sharesies.Credentials{Username: "username", Password: "password"})
+	assert.Nil(t, err)
+
+	for fundID, amount := range map[string]int64{fundA: 700, fundB: 200} {
+		c, err := s.CostBuy(ctx, fundID, sharesies.NewDecimal(amount, 0))
+		assert.Nil(t, err)
+		_, err = s.Buy(ctx, c)
+		assert.Nil(t, err)
+	}
+
+	r := &rebalance.Rebalancer{
+		Client:  s,
+		Targets: half(),
+		Options: &rebalance.Options{
+			Tolerance:   decimal("5"),
+			Cash:        acc.Balance("nzd"),
+			SellFeeRate: sharesiestest.DefaultFeeRate,
+		},
+	}
+
+	plan, err := r.Plan(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, plan.Trades, 2)
+	assert.True(t, plan.Trades[1].Fee.IsZero())
+
+	assert.Nil(t, r.Execute(ctx, plan))
+	assert.False(t, plan.Trades[1].Fee.IsZero())
+	assert.NotEmpty(t, plan.Trades[0].OrderID)
+	assert.NotEmpty(t, plan.Trades[1].OrderID)
+
+	a := acc.Shares(fundA).Value(decimal("2.00"))
+	b := acc.Shares(fundB).Value(decimal("5.00"))
+	assert.InDelta(t, 1, a.Div(b, 4).Float64(), 0.01)
+	assert.True(t, acc.Balance("nzd").Sign() >= 0)
+	assert.True(t, acc.Balance("nzd").LessThan(decimal("1")), acc.Balance("nzd").String())
+}
+
+func Test_Rebalancer_BuyOnly(t *testing.T) {
+	srv := sharesiestest.NewServer()
+	defer srv.Close()
+
+	a := &sharesies.Company{ID: fundA, Symbol: "AAA", Marketprice: decimal("2.00"), Exchange: "NZX", Exchangecountry: "nzl"}
+	b := &sharesies.Company{ID: fundB, Symbol: "BBB", Marketprice: decimal("5.00"), Exchange: "NZX", Exchangecountry: "nzl"}
+	srv.AddInstrument(a)
+	srv.AddInstrument(b)
+
+	acc := srv.AddUser("username", "password")
+	acc.Deposit("nzd", sharesies.NewDecimal(100, 0))
+
+	ctx := context.Background()
+	s, _ := sharesies.NewWithOptions(srv.Options())
+	_, err := s.Authenticate(ctx, &sharesies.Credentials{Username: "username", Password: "password"})
+	assert.Nil(t, err)
+
+	r := &rebalance.Rebalancer{
+		Client:  s,
+		Targets: rebalance.Targets{fundA: decimal("75"), fundB: decimal("25")},
+		Options: &rebalance.Options{
+			BuyOnly:     true,
+			Cash:        acc.Balance("nzd"),
+			Instruments: map[string]*sharesies.Company{fundA: a, fundB: b},
+		},
+	}
+
+	plan, err := r.Plan(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, plan.Trades, 2)
+
+	assert.True(t, decimal("75").Equal(plan.Trades[0].Amount))
+	assert.True(t, decimal("25").Equal(plan.Trades[1].Amount))
+	assert.False(t, plan.Fees()["nzd"].IsZero())
+
+	assert.Nil(t, r.Execute(ctx, plan))
+	assert.True(t, acc.Balance("nzd").LessThan(decimal("0.1")), acc.Balance("nzd").String())
+}
@@ -0,0 +1,216 @@
+package rebalance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// Client quotes and places the trades, *sharesies.Sharesies satisfies it
+type Client interface {
+	Profile(ctx context.Context) (*sharesies.ProfileResponse, error)
+	CostBuy(ctx context.Context, fundId string, amount sharesies.Decimal) (*sharesies.CostBuyResponse, error)
+	Buy(ctx context.Context, costBuy *sharesies.CostBuyResponse) (*sharesies.ProfileResponse, error)
+	CostSell(ctx context.Context, fundId string, shareAmount sharesies.Shares) (*sharesies.CostSellResponse, error)
+	Sell(ctx context.Context, costSell *sharesies.CostSellResponse) (*sharesies.ProfileResponse, error)
+}
+
+// Rebalancer rebalances the portfolio of the Client toward Targets
+type Rebalancer struct {
+	Client  Client
+	Targets Targets
+	Options *Options
+}
+
+// Plan computes the trades for the current portfolio and quotes them. Buys
+// are scaled down so they cost, fees included, no more than the cash
+// available. Buys paid for by sales can only be quoted once the sales are
+// placed, their fees are left zero until Execute.
+func (r *Rebalancer) Plan(ctx context.Context) (*Plan, error) {
+	p, err := r.Client.Profile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := Compute(p, r.Targets, r.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	sells := false
+	for _, t := range plan.Trades {
+		if t.Side == sharesies.OrderSideSell {
+			sells = true
+			if err := r.quote(ctx, t); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := r.fit(ctx, plan); err != nil {
+		if sells && sharesies.IsInsufficientFunds(err) {
+			for _, t := range plan.Trades {
+				t.buy = nil
+			}
+			return plan, nil
+		}
+
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// fit quotes the buys of plan, scaling them down when they cost more than
+// the wallet they are paid from holds
+func (r *Rebalancer) fit(ctx context.Context, plan *Plan) error {
+	for _, t := range plan.Trades {
+		if t.Side != sharesies.OrderSideBuy {
+			continue
+		}
+
+		if err := r.quote(ctx, t); err != nil {
+			return err
+		}
+	}
+
+	// every buy is scaled by the wallet shortest of cash
+	scale, short := sharesies.NewDecimal(1, 0), false
+	for currency, needed := range payments(plan) {
+		if cash := plan.Cash[currency]; needed.GreaterThan(cash) {
+			if s := cash.Div(needed, 8); !short || s.LessThan(scale) {
+				scale, short = s, true
+			}
+		}
+	}
+
+	if !short {
+		return nil
+	}
+
+	for _, t := range plan.Trades {
+		if t.Side != sharesies.OrderSideBuy {
+			continue
+		}
+
+		t.Amount = t.Amount.Mul(scale).Truncate(2)
+		if err := r.quote(ctx, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// payments returns what the quoted buys of plan take from each wallet
+func payments(plan *Plan) map[string]sharesies.Decimal {
+	total := map[string]sharesies.Decimal{}
+	for _, t := range plan.Trades {
+		if t.Side != sharesies.OrderSideBuy || t.buy == nil {
+			continue
+		}
+
+		paid := t.buy.Payments()
+		if len(paid) == 0 {
+			paid = map[string]sharesies.Decimal{t.Currency: t.buy.TotalCost}
+		}
+
+		for currency, amount := range paid {
+			total[currency] = total[currency].Add(amount)
+		}
+	}
+
+	return total
+}
+
+func (r *Rebalancer) quote(ctx context.Context, t *Trade) error {
+	if t.Side == sharesies.OrderSideSell {
+		sell, err := r.Client.CostSell(ctx, t.FundID, t.Shares)
+		if err != nil {
+			return fmt.Errorf("quoting sell of %s: %w", t.FundID, err)
+		}
+
+		t.sell = sell
+		return nil
+	}
+
+	buy, err := r.Client.CostBuy(ctx, t.FundID, t.Amount)
+	if err != nil {
+		return fmt.Errorf("quoting buy of %s: %w", t.FundID, err)
+	}
+
+	t.buy, t.Fee = buy, buy.ExpectedFee
+	return nil
+}
+
+// Execute places the sells of plan and then its buys, stopping at the first
+// trade failing. Trades already placed are skipped, so a failed Execute can
+// be retried with the same plan.
+func (r *Rebalancer) Execute(ctx context.Context, plan *Plan) error {
+	// the orders known before trading tell the ones placed by Execute apart
+	before, err := r.Client.Profile(ctx)
+	if err != nil {
+		return err
+	}
+
+	quoted := true
+	for _, t := range plan.Trades {
+		if t.Side == sharesies.OrderSideBuy {
+			quoted = quoted && t.buy != nil
+			continue
+		}
+
+		if t.sell == nil && !t.placed {
+			if err := r.quote(ctx, t); err != nil {
+				return err
+			}
+		}
+
+		if before, err = r.place(ctx, t, before); err != nil {
+			return err
+		}
+	}
+
+	if !quoted {
+		if err := r.fit(ctx, plan); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range plan.Trades {
+		if t.Side == sharesies.OrderSideBuy {
+			if before, err = r.place(ctx, t, before); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// place places t unless it already was, returning the profile after it
+func (r *Rebalancer) place(ctx context.Context, t *Trade, before *sharesies.ProfileResponse) (*sharesies.ProfileResponse, error) {
+	if t.placed {
+		return before, nil
+	}
+
+	var p *sharesies.ProfileResponse
+	var err error
+	if t.Side == sharesies.OrderSideSell {
+		p, err = r.Client.Sell(ctx, t.sell)
+	} else {
+		p, err = r.Client.Buy(ctx, t.buy)
+	}
+
+	if err != nil {
+		return before, fmt.Errorf("%s %s: %w", t.Side, t.FundID, err)
+	}
+
+	t.placed = true
+	if o := p.PlacedOrder(before, t.FundID, t.Side); o != nil {
+		t.OrderID = o.ID
+	}
+
+	return p, nil
+}
@@ -0,0 +1,123 @@
+package sharesies
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides whether a failed request should be sent again
+type RetryPolicy interface {
+	// Backoff is called after the attempt-th failed attempt with its error and
+	// returns how long to wait before trying again, or false to give up.
+	Backoff(attempt int, err error) (time.Duration, bool)
+}
+
+// NoRetry is a RetryPolicy that never retries
+var NoRetry RetryPolicy = noRetry{}
+
+type noRetry struct{}
+
+func (noRetry) Backoff(int, error) (time.Duration, bool) {
+	return 0, false
+}
+
+// ExponentialBackoff retries transport errors, 429 and 5xx responses doubling
+// the delay on every attempt with random jitter. A Retry-After sent by
+// Sharesies takes precedence over the computed delay.
+type ExponentialBackoff struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used by NewWithOptions when none is given
+func DefaultRetryPolicy() RetryPolicy {
+	return &ExponentialBackoff{
+		MaxRetries: 3,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   10 * time.Second,
+	}
+}
+
+func (b *ExponentialBackoff) Backoff(attempt int, err error) (time.Duration, bool) {
+	if attempt > b.MaxRetries || !temporary(err) {
+		return 0, false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, true
+	}
+
+	d := b.BaseDelay << (attempt - 1)
+	if d <= 0 || (b.MaxDelay > 0 && d > b.MaxDelay) {
+		d = b.MaxDelay
+	}
+
+	if d <= 0 {
+		return 0, true
+	}
+
+	// Equal jitter: keep half of the delay and randomise the rest
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1)), true
+}
+
+// responseError is a failure reading or decoding a successful response, the
+// request was already processed by Sharesies so it is never sent again
+type responseError struct {
+	err error
+}
+
+func (e *responseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *responseError) Unwrap() error {
+	return e.err
+}
+
+// temporary reports whether err may go away by sending the request again,
+// that is a transport failure before any response or a rate limited/server
+// error response
+func temporary(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var respErr *responseError
+	if errors.As(err, &respErr) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	// *url.Error, returned by http.Client for transport failures, is a net.Error
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func parseRetryAfter(h http.Header, now time.Time) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
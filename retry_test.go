@@ -0,0 +1,144 @@
+package sharesies_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+func Test_ExponentialBackoff(t *testing.T) {
+	b := &sharesies.ExponentialBackoff{MaxRetries: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	unavailable := &sharesies.APIError{StatusCode: http.StatusServiceUnavailable}
+
+	d, ok := b.Backoff(1, unavailable)
+	assert.True(t, ok)
+	assert.True(t, d >= 50*time.Millisecond && d <= 100*time.Millisecond)
+
+	d, ok = b.Backoff(3, unavailable)
+	assert.True(t, ok)
+	assert.True(t, d >= 150*time.Millisecond && d <= 300*time.Millisecond)
+
+	_, ok = b.Backoff(4, unavailable)
+	assert.False(t, ok)
+}
+
+func Test_ExponentialBackoff_RetryAfter(t *testing.T) {
+	b := &sharesies.ExponentialBackoff{MaxRetries: 3, BaseDelay: 100 * time.Millisecond}
+
+	d, ok := b.Backoff(1, &sharesies.APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * time.Second})
+
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, d)
+}
+
+func Test_ExponentialBackoff_Permanent(t *testing.T) {
+	b := sharesies.DefaultRetryPolicy()
+
+	_, ok := b.Backoff(1, &sharesies.APIError{StatusCode: http.StatusBadRequest})
+	assert.False(t, ok)
+
+	_, ok = b.Backoff(1, context.Canceled)
+	assert.False(t, ok)
+
+	_, ok = b.Backoff(1, &url.Error{Op: "Post", URL: "https://app.sharesies.nz", Err: errors.New("connection reset by peer")})
+	assert.True(t, ok)
+
+	_, ok = b.Backoff(1, errors.New("unexpected end of JSON input"))
+	assert.False(t, ok)
+}
+
+func Test_Request_NoRetryAfterSuccess(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte("{"))
+	}))
+	defer srv.Close()
+
+	s, err := sharesies.NewWithOptions(&sharesies.Options{
+		AppURL:      srv.URL,
+		RetryPolicy: &sharesies.ExponentialBackoff{MaxRetries: 3, BaseDelay: time.Millisecond},
+	})
+	assert.Nil(t, err)
+
+	_, err = s.Authenticate(context.Background(), &sharesies.Credentials{Username: "username", Password: "password"})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, hits)
+}
+
+func Test_NoRetry(t *testing.T) {
+	_, ok := sharesies.NoRetry.Backoff(1, &sharesies.APIError{StatusCode: http.StatusServiceUnavailable})
+
+	assert.False(t, ok)
+}
+
+func Test_Buy_Retry(t *testing.T) {
+	srv, acc, s := newFakeServer(t)
+	ctx := context.Background()
+
+	costBuy, err := s.CostBuy(ctx, fakeFundID, sharesies.NewDecimal(50, 0))
+	assert.Nil(t, err)
+
+	srv.Fail("/api/order/create-buy", http.StatusServiceUnavailable, 2)
+
+	_, err = s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, srv.Requests("/api/order/create-buy"))
+	assert.Equal(t, "50.00", acc.Balance("nzd").String())
+}
+
+func Test_Buy_RetryExhausted(t *testing.T) {
+	srv, acc, s := newFakeServer(t)
+	ctx := context.Background()
+
+	costBuy, err := s.CostBuy(ctx, fakeFundID, sharesies.NewDecimal(50, 0))
+	assert.Nil(t, err)
+
+	srv.Fail("/api/order/create-buy", http.StatusServiceUnavailable, 3)
+
+	_, err = s.Buy(ctx, costBuy)
+	assert.True(t, sharesies.IsRetryable(err))
+	assert.Equal(t, 3, srv.Requests("/api/order/create-buy"))
+	assert.Equal(t, "100", acc.Balance("nzd").String())
+}
+
+func Test_Buy_Replay(t *testing.T) {
+	_, acc, s := newFakeServer(t)
+	ctx := context.Background()
+
+	costBuy, err := s.CostBuy(ctx, fakeFundID, sharesies.NewDecimal(50, 0))
+	assert.Nil(t, err)
+
+	_, err = s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+
+	_, err = s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+	assert.Equal(t, "50.00", acc.Balance("nzd").String())
+}
+
+func Test_Sell_Replay(t *testing.T) {
+	_, acc, s := newFakeServer(t)
+	ctx := context.Background()
+
+	costBuy, _ := s.CostBuy(ctx, fakeFundID, sharesies.NewDecimal(50, 0))
+	s.Buy(ctx, costBuy)
+
+	costSell, err := s.CostSell(ctx, fakeFundID, sharesies.MustParseShares("10"))
+	assert.Nil(t, err)
+
+	_, err = s.Sell(ctx, costSell)
+	assert.Nil(t, err)
+
+	_, err = s.Sell(ctx, costSell)
+	assert.Nil(t, err)
+	assert.Equal(t, "14.875000", acc.Shares(fakeFundID).String())
+}
@@ -0,0 +1,260 @@
+package sharesies
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var ErrNoSession = errors.New("no saved session")
+var ErrInvalidKey = errors.New("session key must be 16, 24 or 32 bytes")
+
+// Session is everything needed to resume an authenticated Client without logging in again
+type Session struct {
+	DistillToken string           `json:"distill_token"`
+	Cookies      []*http.Cookie   `json:"cookies"`
+	Profile      *ProfileResponse `json:"profile"`
+	SavedAt      time.Time        `json:"saved_at"`
+}
+
+// SessionStore persists a Session between runs
+type SessionStore interface {
+	// Load returns the saved Session or ErrNoSession
+	Load(ctx context.Context) (*Session, error)
+	Save(ctx context.Context, session *Session) error
+	Clear(ctx context.Context) error
+}
+
+// MemorySessionStore keeps the Session in memory, useful to share a session between clients
+type MemorySessionStore struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+// NewMemorySessionStore returns an empty MemorySessionStore
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{}
+}
+
+func (m *MemorySessionStore) Load(ctx context.Context) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data == nil {
+		return nil, ErrNoSession
+	}
+
+	sess := &Session{}
+	return sess, json.Unmarshal(m.data, sess)
+}
+
+func (m *MemorySessionStore) Save(ctx context.Context, session *Session) error {
+	b, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data = b
+	return nil
+}
+
+func (m *MemorySessionStore) Clear(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data = nil
+	return nil
+}
+
+// FileSessionStore saves the Session to a file encrypted with AES-GCM
+type FileSessionStore struct {
+	path string
+	aead cipher.AEAD
+	mu   sync.Mutex
+}
+
+// NewFileSessionStore returns a FileSessionStore writing to path, key must be 16, 24 or 32 bytes
+func NewFileSessionStore(path string, key []byte) (*FileSessionStore, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSessionStore{path: path, aead: aead}, nil
+}
+
+func (f *FileSessionStore) Load(ctx context.Context) (*Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return nil, ErrNoSession
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	n := f.aead.NonceSize()
+	if len(b) < n {
+		return nil, ErrNoSession
+	}
+
+	plain, err := f.aead.Open(nil, b[:n], b[n:], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sess := &Session{}
+	return sess, json.Unmarshal(plain, sess)
+}
+
+func (f *FileSessionStore) Save(ctx context.Context, session *Session) error {
+	plain, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, f.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(f.aead.Seal(nonce, nonce, plain, nil)); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// Replace the file atomically so a crash never leaves a truncated session
+	return os.Rename(tmp.Name(), f.path)
+}
+
+func (f *FileSessionStore) Clear(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	err := os.Remove(f.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// Resume restores the Session saved in the SessionStore, its cookies, distill
+// token and profile, and validates it through Profile, saving the refreshed
+// session on success. An expired session is cleared from the store.
+func (s *Sharesies) Resume(ctx context.Context) (*ProfileResponse, error) {
+	if s.SessionStore == nil {
+		return nil, ErrNoSession
+	}
+
+	sess, err := s.SessionStore.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if jar, u := s.cookieJar(); jar != nil {
+		for _, c := range sess.Cookies {
+			c.Path = "/"
+		}
+
+		jar.SetCookies(u, sess.Cookies)
+	}
+
+	if sess.DistillToken != "" && sess.Profile != nil {
+		restored, err := newTokenSession(sess.DistillToken, sess.Profile)
+		if err != nil {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		s.session = restored
+		s.mu.Unlock()
+	}
+
+	p, err := s.Profile(ctx)
+	if errors.Is(err, ErrAuthentication) || IsUnauthorized(err) {
+		// The saved session expired, drop it so the next run logs in again
+		if err := s.SessionStore.Clear(ctx); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrAuthentication
+	}
+
+	return p, err
+}
+
+func (s *Sharesies) saveSession(ctx context.Context, current *tokenSession) error {
+	if s.SessionStore == nil {
+		return nil
+	}
+
+	sess := &Session{
+		DistillToken: current.token.Raw,
+		Profile:      current.profile,
+		SavedAt:      time.Now(),
+	}
+
+	if jar, u := s.cookieJar(); jar != nil {
+		sess.Cookies = jar.Cookies(u)
+	}
+
+	return s.SessionStore.Save(ctx, sess)
+}
+
+// cookieJar returns the jar of the underlying http.Client and the URL its session cookies belong to
+func (s *Sharesies) cookieJar() (http.CookieJar, *url.URL) {
+	c, ok := s.HttpClient.(*http.Client)
+	if !ok || c.Jar == nil {
+		return nil, nil
+	}
+
+	u, err := url.Parse(s.endpoints().IdentityCheck)
+	if err != nil {
+		return nil, nil
+	}
+
+	u.Path = "/"
+	return c.Jar, u
+}
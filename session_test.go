@@ -0,0 +1,142 @@
+package sharesies_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/sharesiestest"
+)
+
+var sessionKey = []byte("0123456789abcdef0123456789abcdef")
+
+func Test_MemorySessionStore(t *testing.T) {
+	ctx := context.Background()
+	store := sharesies.NewMemorySessionStore()
+
+	_, err := store.Load(ctx)
+	assert.Equal(t, sharesies.ErrNoSession, err)
+
+	err = store.Save(ctx, &sharesies.Session{DistillToken: "token"})
+	assert.Nil(t, err)
+
+	sess, err := store.Load(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "token", sess.DistillToken)
+
+	assert.Nil(t, store.Clear(ctx))
+	_, err = store.Load(ctx)
+	assert.Equal(t, sharesies.ErrNoSession, err)
+}
+
+func Test_FileSessionStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "session")
+
+	store, err := sharesies.NewFileSessionStore(path, sessionKey)
+	assert.Nil(t, err)
+
+	_, err = store.Load(ctx)
+	assert.Equal(t, sharesies.ErrNoSession, err)
+
+	err = store.Save(ctx, &sharesies.Session{DistillToken: "token"})
+	assert.Nil(t, err)
+
+	sess, err := store.Load(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "token", sess.DistillToken)
+
+	other, _ := sharesies.NewFileSessionStore(path, []byte("fedcba9876543210fedcba9876543210"))
+	_, err = other.Load(ctx)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, store.Clear(ctx))
+	_, err = store.Load(ctx)
+	assert.Equal(t, sharesies.ErrNoSession, err)
+}
+
+func Test_NewFileSessionStore_InvalidKey(t *testing.T) {
+	_, err := sharesies.NewFileSessionStore("session", []byte("short"))
+
+	assert.Equal(t, sharesies.ErrInvalidKey, err)
+}
+
+func Test_Resume(t *testing.T) {
+	srv := sharesiestest.NewServer()
+	defer srv.Close()
+	srv.AddUser("username", "password")
+
+	ctx := context.Background()
+	store, _ := sharesies.NewFileSessionStore(filepath.Join(t.TempDir(), "session"), sessionKey)
+
+	opts := srv.Options()
+	opts.SessionStore = store
+
+	s, _ := sharesies.NewWithOptions(opts)
+	_, err := s.Authenticate(ctx, &sharesies.Credentials{Username: "username", Password: "password"})
+	assert.Nil(t, err)
+
+	resumed, _ := sharesies.NewWithOptions(opts)
+	p, err := resumed.Resume(ctx)
+
+	assert.Nil(t, err)
+	assert.True(t, p.Authenticated)
+	assert.Equal(t, 1, srv.Requests("/api/identity/login"))
+
+	_, err = resumed.Instruments(ctx, &sharesies.InstrumentsRequest{Page: 1, Perpage: 10})
+	assert.Nil(t, err)
+}
+
+func Test_Resume_NoSession(t *testing.T) {
+	opts := &sharesies.Options{SessionStore: sharesies.NewMemorySessionStore()}
+	s, _ := sharesies.NewWithOptions(opts)
+
+	_, err := s.Resume(context.Background())
+
+	assert.Equal(t, sharesies.ErrNoSession, err)
+}
+
+func Test_Resume_Expired(t *testing.T) {
+	srv := sharesiestest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	store := sharesies.NewMemorySessionStore()
+	store.Save(ctx, &sharesies.Session{})
+
+	opts := srv.Options()
+	opts.SessionStore = store
+
+	s, _ := sharesies.NewWithOptions(opts)
+	_, err := s.Resume(ctx)
+
+	assert.Equal(t, sharesies.ErrAuthentication, err)
+
+	_, err = store.Load(ctx)
+	assert.Equal(t, sharesies.ErrNoSession, err)
+}
+
+func Test_Resume_Unauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"type":"error","code":"unauthorized","message":"session expired"}`))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	store := sharesies.NewMemorySessionStore()
+	store.Save(ctx, &sharesies.Session{})
+
+	s, _ := sharesies.NewWithOptions(&sharesies.Options{AppURL: srv.URL, DataURL: srv.URL, SessionStore: store})
+	_, err := s.Resume(ctx)
+
+	assert.Equal(t, sharesies.ErrAuthentication, err)
+
+	_, err = store.Load(ctx)
+	assert.Equal(t, sharesies.ErrNoSession, err)
+}
@@ -5,24 +5,21 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/cookiejar"
+	"sync"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
-	"github.com/google/uuid"
 )
 
 const (
-	endpointIdentityLogin  = "https://app.sharesies.nz/api/identity/login"
-	endpointIdentityCheck  = "https://app.sharesies.nz/api/identity/check"
-	endpointIdentityReAuth = "https://app.sharesies.nz/api/identity/reauthenticate"
-	endpointInstruments    = "https://data.sharesies.nz/api/v1/instruments"
-	endpointCostBuy        = "https://app.sharesies.nz/api/order/cost-buy"
-	endpointCreateBuy      = "https://app.sharesies.nz/api/order/create-buy"
-	endpointCostSell       = "https://app.sharesies.nz/api/order/cost-sell"
-	endpointCreateSell     = "https://app.sharesies.nz/api/order/create-sell"
+	DefaultAppURL  = "https://app.sharesies.nz"
+	DefaultDataURL = "https://data.sharesies.nz"
+
+	// DefaultRefreshSkew is how long before the distill token expires the session is refreshed
+	DefaultRefreshSkew = time.Minute
 )
 
 type Map map[string]interface{}
@@ -30,15 +27,54 @@ type Map map[string]interface{}
 var ErrNoJarDefine = errors.New("HttpClient must have a cookie jar defined")
 var ErrAuthentication = errors.New("authentication failed")
 var ErrHttpRequest = errors.New("request to sharesies failed")
+var ErrNotAuthenticated = errors.New("not authenticated")
 
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// Sharesies is a Client safe for concurrent use by multiple goroutines
 type Sharesies struct {
 	HttpClient HTTPClient
+	Endpoints  *Endpoints
+	// RetryPolicy applied to every request, nil disables retries
+	RetryPolicy RetryPolicy
+	// SessionStore persists the session after every authentication, nil disables it
+	SessionStore SessionStore
+	// TOTPProvider answers MFA challenges during Authenticate, nil returns them to the caller
+	TOTPProvider TOTPProvider
+	// RefreshSkew is how long before the token expires the session is refreshed, defaults to DefaultRefreshSkew
+	RefreshSkew time.Duration
+
+	// mu guards the authentication state below
+	mu         sync.Mutex
 	creds      *Credentials
+	pendingMFA *Credentials
+	accountID  string
 	session    *tokenSession
+	refreshing *refreshCall
+}
+
+// Options configures a Sharesies Client created with NewWithOptions
+type Options struct {
+	// HttpClient used to issue requests, it must have a cookie jar defined.
+	// A client with a fresh cookie jar is created when nil.
+	HttpClient *http.Client
+	// AppURL is the base URL of the Sharesies app API, defaults to DefaultAppURL
+	AppURL string
+	// DataURL is the base URL of the Sharesies data API, defaults to DefaultDataURL
+	DataURL string
+	// RetryPolicy applied to every request, defaults to DefaultRetryPolicy.
+	// Use NoRetry to disable retries.
+	RetryPolicy RetryPolicy
+	// SessionStore persists the session so it can be restored with Resume
+	SessionStore SessionStore
+	// Credentials used to re-authenticate a session restored with Resume
+	Credentials *Credentials
+	// TOTPProvider answers MFA challenges so unattended logins work with 2FA enabled
+	TOTPProvider TOTPProvider
+	// RefreshSkew is how long before the token expires the session is refreshed, defaults to DefaultRefreshSkew
+	RefreshSkew time.Duration
 }
 
 // Credentials Sharesies
@@ -47,13 +83,32 @@ type Credentials struct {
 	Password string
 }
 
+// tokenSession is never modified once created, it is replaced as a whole
 type tokenSession struct {
-	token   *jwt.Token
+	token     *jwt.Token
+	expiresAt time.Time
+	profile   *ProfileResponse
+}
+
+// refreshCall is a re-authentication in flight shared by every caller needing it
+type refreshCall struct {
+	done    chan struct{}
 	profile *ProfileResponse
+	err     error
 }
 
 // New returns a new Sharesies Client instance
 func New(client *http.Client) (*Sharesies, error) {
+	return NewWithOptions(&Options{HttpClient: client})
+}
+
+// NewWithOptions returns a new Sharesies Client instance configured by opts
+func NewWithOptions(opts *Options) (*Sharesies, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	client := opts.HttpClient
 	if client == nil {
 		j, err := cookiejar.New(nil)
 		if err != nil {
@@ -69,33 +124,47 @@ func New(client *http.Client) (*Sharesies, error) {
 		return nil, ErrNoJarDefine
 	}
 
+	appURL := opts.AppURL
+	if appURL == "" {
+		appURL = DefaultAppURL
+	}
+
+	dataURL := opts.DataURL
+	if dataURL == "" {
+		dataURL = DefaultDataURL
+	}
+
+	retryPolicy := opts.RetryPolicy
+	if retryPolicy == nil {
+		retryPolicy = DefaultRetryPolicy()
+	}
+
 	return &Sharesies{
-		client,
-		nil,
-		nil,
+		HttpClient:   client,
+		Endpoints:    NewEndpoints(appURL, dataURL),
+		RetryPolicy:  retryPolicy,
+		SessionStore: opts.SessionStore,
+		TOTPProvider: opts.TOTPProvider,
+		RefreshSkew:  opts.RefreshSkew,
+		creds:        opts.Credentials,
 	}, nil
 }
 
+// Authenticate logs in with creds. Accounts with 2FA enabled return an
+// *MFAChallenge unless a TOTPProvider is configured to answer it.
 func (s *Sharesies) Authenticate(ctx context.Context, creds *Credentials) (*ProfileResponse, error) {
-	p := &ProfileResponse{}
-	body := &Map{"email": creds.Username, "password": creds.Password, "remember": true}
+	p, err := s.login(ctx, creds, "")
 
-	err := s.request(ctx, http.MethodPost, nil, endpointIdentityLogin, body, p)
-	if err != nil {
-		return nil, err
-	}
-
-	if !p.Authenticated {
-		return nil, ErrAuthentication
-	}
+	var challenge *MFAChallenge
+	if errors.As(err, &challenge) && s.TOTPProvider != nil {
+		code, err := s.TOTPProvider.Code(ctx)
+		if err != nil {
+			return nil, err
+		}
 
-	err = s.authenticated(p)
-	if err != nil {
-		return nil, err
+		return s.SubmitMFA(ctx, code)
 	}
 
-	s.creds = creds
-
 	return p, err
 }
 
@@ -103,12 +172,16 @@ func (s *Sharesies) Authenticate(ctx context.Context, creds *Credentials) (*Prof
 func (s *Sharesies) Profile(ctx context.Context) (*ProfileResponse, error) {
 	p := &ProfileResponse{}
 
-	err := s.request(ctx, http.MethodGet, nil, endpointIdentityCheck, nil, p)
+	err := s.request(ctx, http.MethodGet, nil, s.endpoints().IdentityCheck, nil, p)
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.authenticated(p)
+	if !p.Authenticated {
+		return nil, ErrAuthentication
+	}
+
+	err = s.authenticated(ctx, p)
 	if err != nil {
 		return nil, err
 	}
@@ -119,116 +192,198 @@ func (s *Sharesies) Profile(ctx context.Context) (*ProfileResponse, error) {
 // Instruments returns Companies/Funds listed on Sharesies
 func (s *Sharesies) Instruments(ctx context.Context, request *InstrumentsRequest) (*InstrumentResponse, error) {
 	r := &InstrumentResponse{}
-	h, err := s.headers(ctx)
-	if err != nil {
-		return nil, err
-	}
 
-	err = s.request(ctx, http.MethodPost, h, endpointInstruments, request, r)
+	err := s.authRequest(ctx, http.MethodPost, s.endpoints().Instruments, true, request, r)
 	return r, err
 }
 
 // CostBuy return Cost to buy stocks from the NZX Market
-func (s *Sharesies) CostBuy(ctx context.Context, fundId string, amount float64) (*CostBuyResponse, error) {
-	r := &CostBuyResponse{}
-	o := &OrderBuy{Type: OrderTypeDollarMarket, CurrencyAmount: fmt.Sprintf("%.2f", amount)}
-	cr := &CostBuyRequest{
-		FundID:     fundId,
-		ActingAsID: s.session.profile.UserList[0].ID,
-		Order:      o,
-	}
-
-	s.reAuthenticate(ctx)
+func (s *Sharesies) CostBuy(ctx context.Context, fundId string, amount Decimal) (*CostBuyResponse, error) {
+	return s.account().CostBuy(ctx, fundId, amount)
+}
 
-	err := s.request(ctx, http.MethodPost, nil, endpointCostBuy, cr, r)
-	return r, err
+// CostBuyShares return Cost to buy exactly shareAmount shares of a fund
+func (s *Sharesies) CostBuyShares(ctx context.Context, fundId string, shareAmount Shares) (*CostBuyResponse, error) {
+	return s.account().CostBuyShares(ctx, fundId, shareAmount)
 }
 
-// Buy purchase stocks from the NZX Market
+// Buy purchase stocks from the NZX Market. Buying the same costBuy again
+// reuses its idempotency key so the order is never placed twice.
 func (s *Sharesies) Buy(ctx context.Context, costBuy *CostBuyResponse) (*ProfileResponse, error) {
-	r := &ProfileResponse{}
+	return s.account().Buy(ctx, costBuy)
+}
 
-	br := &CreateBuyRequest{
-		FundID:           costBuy.FundID,
-		ActingAsID:       s.session.profile.UserList[0].ID,
-		Order:            costBuy.Request,
-		PaymentBreakdown: costBuy.PaymentBreakdown,
-		IdempotencyKey:   uuid.NewString(),
-		ExpectedFee:      costBuy.ExpectedFee,
-	}
+// CostSell return Cost to sell shareAmount shares of a fund
+func (s *Sharesies) CostSell(ctx context.Context, fundId string, shareAmount Shares) (*CostSellResponse, error) {
+	return s.account().CostSell(ctx, fundId, shareAmount)
+}
 
-	s.reAuthenticate(ctx)
+// CostSellDollars return Cost to sell as many shares of a fund as amount is worth
+func (s *Sharesies) CostSellDollars(ctx context.Context, fundId string, amount Decimal) (*CostSellResponse, error) {
+	return s.account().CostSellDollars(ctx, fundId, amount)
+}
 
-	err := s.request(ctx, http.MethodPost, nil, endpointCreateBuy, br, r)
-	return r, err
+// Sell sells stocks quoted by CostSell. Selling the same sellBuy again
+// reuses its idempotency key so the order is never placed twice.
+func (s *Sharesies) Sell(ctx context.Context, sellBuy *CostSellResponse) (*ProfileResponse, error) {
+	return s.account().Sell(ctx, sellBuy)
+}
+
+func (s *Sharesies) endpoints() *Endpoints {
+	if s.Endpoints == nil {
+		return defaultEndpoints
+	}
+
+	return s.Endpoints
 }
 
-func (s *Sharesies) CostSell(ctx context.Context, foundId string, shareAmount float64) (*CostSellResponse, error) {
-	r := &CostSellResponse{}
-	o := &OrderSell{Type: OrderTypeShareMarket, ShareAmount: fmt.Sprintf("%.6f", shareAmount)}
-	sr := &CostSellRequest{FundID: foundId, ActingAsID: s.session.profile.UserList[0].ID, Order: o}
+func (s *Sharesies) authenticated(ctx context.Context, p *ProfileResponse) error {
+	sess, err := newTokenSession(p.DistillToken, p)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.session = sess
+	s.mu.Unlock()
+
+	return s.saveSession(ctx, sess)
+}
 
-	_, err := s.reAuthenticate(ctx)
+// newTokenSession returns the session of the distill token issued with profile p
+func newTokenSession(distillToken string, p *ProfileResponse) (*tokenSession, error) {
+	claims := &jwt.StandardClaims{}
+	token, _, err := new(jwt.Parser).ParseUnverified(distillToken, claims)
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.request(ctx, http.MethodPost, nil, endpointCostSell, sr, r)
-	return r, err
+	sess := &tokenSession{
+		token:   token,
+		profile: p,
+	}
+
+	if claims.ExpiresAt > 0 {
+		sess.expiresAt = time.Unix(claims.ExpiresAt, 0)
+	}
+
+	return sess, nil
 }
 
-func (s *Sharesies) Sell(ctx context.Context, sellBuy *CostSellResponse) (*ProfileResponse, error) {
-	r := &ProfileResponse{}
+func (s *Sharesies) currentSession() (*tokenSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	sr := CreateSellRequest{
-		FundID: sellBuy.FundID,
-		ActingAsID: s.session.profile.UserList[0].ID,
-		Order: sellBuy.Request,
+	if s.session == nil {
+		return nil, ErrNotAuthenticated
 	}
 
-	_, err := s.reAuthenticate(ctx)
+	return s.session, nil
+}
+
+// validSession returns the current session, refreshing it first when its
+// token expires within RefreshSkew
+func (s *Sharesies) validSession(ctx context.Context) (*tokenSession, error) {
+	sess, err := s.currentSession()
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.request(ctx, http.MethodPost, nil, endpointCreateSell, sr, r)
-	return r, err
+	skew := s.RefreshSkew
+	if skew <= 0 {
+		skew = DefaultRefreshSkew
+	}
+
+	if sess.expiresAt.IsZero() || time.Now().Add(skew).Before(sess.expiresAt) {
+		return sess, nil
+	}
+
+	if _, err := s.reAuthenticate(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.currentSession()
 }
 
-func (s *Sharesies) authenticated(p *ProfileResponse) error {
-	claims := &jwt.StandardClaims{}
-	token, _, err := new(jwt.Parser).ParseUnverified(p.DistillToken, claims)
+// authRequest sends a request on behalf of the authenticated session. The
+// session is refreshed ahead of its expiry, or once if Sharesies answers 401.
+// bearer sends the distill token, required by the data API.
+func (s *Sharesies) authRequest(ctx context.Context, method string, url string, bearer bool, body interface{}, response interface{}) error {
+	sess, err := s.validSession(ctx)
 	if err != nil {
-		return nil
+		return err
 	}
 
-	s.session = &tokenSession{
-		token:   token,
-		profile: p,
+	err = s.request(ctx, method, sess.headers(bearer), url, body, response)
+	if !IsUnauthorized(err) {
+		return err
 	}
 
-	return nil
+	if _, err := s.reAuthenticate(ctx); err != nil {
+		return err
+	}
+
+	if sess, err = s.currentSession(); err != nil {
+		return err
+	}
+
+	return s.request(ctx, method, sess.headers(bearer), url, body, response)
 }
 
-func (s *Sharesies) headers(ctx context.Context) (map[string]string, error) {
-	err := s.session.token.Claims.Valid()
-	if err != nil {
-		_, err := s.reAuthenticate(ctx)
-		if err != nil {
-			return nil, err
-		}
+func (t *tokenSession) headers(bearer bool) map[string]string {
+	if !bearer {
+		return nil
 	}
 
 	return map[string]string{
-		"Authorization": "Bearer " + s.session.token.Raw,
-	}, nil
+		"Authorization": "Bearer " + t.token.Raw,
+	}
 }
 
+// reAuthenticate refreshes the session, concurrent callers share a single
+// request to Sharesies instead of each sending their own
 func (s *Sharesies) reAuthenticate(ctx context.Context) (*ProfileResponse, error) {
+	s.mu.Lock()
+	if call := s.refreshing; call != nil {
+		s.mu.Unlock()
+
+		select {
+		case <-call.done:
+			return call.profile, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	call := &refreshCall{done: make(chan struct{})}
+	s.refreshing = call
+	creds, sess, accountID := s.creds, s.session, s.accountID
+	s.mu.Unlock()
+
+	call.profile, call.err = s.refresh(ctx, creds, sess, accountID)
+
+	s.mu.Lock()
+	s.refreshing = nil
+	s.mu.Unlock()
+	close(call.done)
+
+	return call.profile, call.err
+}
+
+func (s *Sharesies) refresh(ctx context.Context, creds *Credentials, sess *tokenSession, accountID string) (*ProfileResponse, error) {
+	if creds == nil || sess == nil {
+		return nil, ErrAuthentication
+	}
+
+	accountID, err := sess.accountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+
 	p := &ProfileResponse{}
-	body := &Map{"password": s.creds.Password, "acting_as_id": s.session.profile.UserList[0].ID}
+	body := &Map{"password": creds.Password, "acting_as_id": accountID}
 
-	err := s.request(ctx, http.MethodPost, nil, endpointIdentityReAuth, body, p)
+	err = s.request(ctx, http.MethodPost, nil, s.endpoints().IdentityReAuth, body, p)
 	if err != nil {
 		return nil, err
 	}
@@ -237,7 +392,7 @@ func (s *Sharesies) reAuthenticate(ctx context.Context) (*ProfileResponse, error
 		return nil, ErrAuthentication
 	}
 
-	e := s.authenticated(p)
+	e := s.authenticated(ctx, p)
 	if e != nil {
 		return nil, e
 	}
@@ -251,6 +406,30 @@ func (s *Sharesies) request(ctx context.Context, method string, headers map[stri
 		return err
 	}
 
+	// The body is encoded once so every retry replays the exact same
+	// request, including any idempotency key
+	for attempt := 1; ; attempt++ {
+		err = s.do(ctx, method, headers, url, b, response)
+		if err == nil || s.RetryPolicy == nil {
+			return err
+		}
+
+		delay, ok := s.RetryPolicy.Backoff(attempt, err)
+		if !ok {
+			return err
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+func (s *Sharesies) do(ctx context.Context, method string, headers map[string]string, url string, b []byte, response interface{}) error {
 	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(b))
 	if err != nil {
 		return err
@@ -269,18 +448,23 @@ func (s *Sharesies) request(ctx context.Context, method string, headers map[stri
 		return err
 	}
 
-	if res.StatusCode != http.StatusOK {
-		return ErrHttpRequest
+	var bd []byte
+	if res.Body != nil {
+		defer res.Body.Close()
+
+		bd, err = ioutil.ReadAll(res.Body)
+		if err != nil && res.StatusCode == http.StatusOK {
+			return &responseError{err}
+		}
 	}
 
-	bd, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return err
+	if res.StatusCode != http.StatusOK {
+		return newAPIError(url, res, bd)
 	}
 
-	if res.Body != nil {
-		defer res.Body.Close()
+	if err := json.Unmarshal(bd, &response); err != nil {
+		return &responseError{err}
 	}
 
-	return json.Unmarshal(bd, &response)
+	return nil
 }
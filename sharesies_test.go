@@ -9,11 +9,13 @@ import (
 	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/sharesiestest"
 )
 
 type MockClient struct {
@@ -29,6 +31,38 @@ func (m *MockClient) Do(req *http.Request) (*http.Response, error) {
 	return args.Get(0).(*http.Response), nil
 }
 
+const fakeFundID = "b8b7ef58-b270-4762-a256-9d68aebc3e23"
+
+// newFakeServer returns a fake server holding one NZX fund and a client
+// logged in to an account with $100
+func newFakeServer(t *testing.T) (*sharesiestest.Server, *sharesiestest.Account, *sharesies.Sharesies) {
+	srv := sharesiestest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddInstrument(&sharesies.Company{
+		ID:              fakeFundID,
+		Symbol:          "AIR",
+		Name:            "Air New Zealand",
+		Marketprice:     sharesies.MustParseDecimal("2.00"),
+		Exchange:        "NZX",
+		Exchangecountry: "nzl",
+	})
+
+	acc := srv.AddUser("username", "password")
+	acc.Deposit("nzd", sharesies.NewDecimal(100, 0))
+
+	opts := srv.Options()
+	opts.RetryPolicy = &sharesies.ExponentialBackoff{MaxRetries: 2, BaseDelay: time.Millisecond}
+
+	s, err := sharesies.NewWithOptions(opts)
+	assert.Nil(t, err)
+
+	_, err = s.Authenticate(context.Background(), &sharesies.Credentials{Username: "username", Password: "password"})
+	assert.Nil(t, err)
+
+	return srv, acc, s
+}
+
 func Test_New(t *testing.T) {
 	s, err := sharesies.New(nil)
 
@@ -55,6 +89,46 @@ func Test_New_HttpClient_MissConfigurated(t *testing.T) {
 	assert.Equal(t, sharesies.ErrNoJarDefine, err)
 }
 
+func Test_NewWithOptions(t *testing.T) {
+	s, err := sharesies.NewWithOptions(&sharesies.Options{
+		AppURL:  "http://localhost:8080/",
+		DataURL: "http://localhost:8081",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "http://localhost:8080/api/identity/login", s.Endpoints.IdentityLogin)
+	assert.Equal(t, "http://localhost:8081/api/v1/instruments", s.Endpoints.Instruments)
+}
+
+func Test_NewWithOptions_HttpClient_MissConfigurated(t *testing.T) {
+	s, err := sharesies.NewWithOptions(&sharesies.Options{HttpClient: http.DefaultClient})
+
+	assert.Nil(t, s)
+	assert.Equal(t, sharesies.ErrNoJarDefine, err)
+}
+
+func Test_Authenticate_Endpoints(t *testing.T) {
+	mockClient := &MockClient{}
+	authUrl, _ := url.Parse("http://localhost:8080/api/identity/login")
+	authBody, _ := os.Open("testdata/authenticated.json")
+	body := marshal(&sharesies.Map{"email": "username", "password": "password", "remember": true})
+
+	mockClient.On("Do", http.MethodPost, authUrl, body).Return(&http.Response{StatusCode: http.StatusOK, Body: authBody}, nil)
+
+	s := sharesies.Sharesies{
+		HttpClient: mockClient,
+		Endpoints:  sharesies.NewEndpoints("http://localhost:8080", "http://localhost:8081"),
+	}
+
+	ctx := context.Background()
+	r, err := s.Authenticate(ctx, &sharesies.Credentials{Username: "username", Password: "password"})
+
+	mockClient.AssertExpectations(t)
+
+	assert.Nil(t, err)
+	assert.True(t, r.Authenticated)
+}
+
 func Test_Authenticate(t *testing.T) {
 	mockClient := &MockClient{}
 	authSuccess(mockClient)
@@ -89,7 +163,8 @@ func Test_Authenticate_Fail(t *testing.T) {
 
 	mockClient.AssertExpectations(t)
 
-	assert.Equal(t, sharesies.ErrHttpRequest, err)
+	assert.ErrorIs(t, err, sharesies.ErrHttpRequest)
+	assert.True(t, sharesies.IsUnauthorized(err))
 	assert.Nil(t, r)
 }
 
@@ -151,16 +226,16 @@ func Test_Instruments(t *testing.T) {
 func Test_CostBuy(t *testing.T) {
 	mockClient := &MockClient{}
 	authSuccess(mockClient)
-	reAuthSuccess(mockClient)
 
 	costBuyUrl, _ := url.Parse("https://app.sharesies.nz/api/order/cost-buy")
 	costBuyBody, _ := os.Open("testdata/costbuy.json")
+	currencyAmount := sharesies.MustParseDecimal("10.00")
 	body := marshal(&sharesies.CostBuyRequest{
 		FundID:     "b8b7ef58-b270-4762-a256-9d68aebc3e23",
 		ActingAsID: "USER_ID",
 		Order: &sharesies.OrderBuy{
 			Type:           sharesies.OrderTypeDollarMarket,
-			CurrencyAmount: "10.00",
+			CurrencyAmount: &currencyAmount,
 		},
 	})
 
@@ -173,25 +248,35 @@ func Test_CostBuy(t *testing.T) {
 	ctx := context.Background()
 	s.Authenticate(ctx, &sharesies.Credentials{Username: "username", Password: "password"})
 
-	i, err := s.CostBuy(ctx, "b8b7ef58-b270-4762-a256-9d68aebc3e23", 10.00)
+	i, err := s.CostBuy(ctx, "b8b7ef58-b270-4762-a256-9d68aebc3e23", sharesies.NewDecimal(10, 0))
 	mockClient.AssertExpectations(t)
 
 	assert.Nil(t, err)
-	assert.NotNil(t, i)
+	assert.Equal(t, "0.05000000", i.ExpectedFee.String())
+	assert.Equal(t, "10", i.TotalCost.String())
+
+	exchange := i.PaymentBreakdown[1]
+	assert.True(t, exchange.IsExchange())
+	assert.Equal(t, "nzd", exchange.Currency)
+	assert.Equal(t, "14.02", exchange.SourceAmount.String())
+	assert.Equal(t, "0.708579", exchange.Rate.String())
+	assert.Equal(t, "0.05605426", exchange.Fee.String())
+	assert.Equal(t, "9.89", exchange.TargetAmount.String())
 }
 
 func Test_CostSell(t *testing.T) {
 	mockClient := &MockClient{}
 	authSuccess(mockClient)
-	reAuthSuccess(mockClient)
 
 	costBuyUrl, _ := url.Parse("https://app.sharesies.nz/api/order/cost-sell")
 	costBuyBody, _ := os.Open("testdata/costsell.json")
+	shares := sharesies.MustParseShares("0.001000")
 	body := marshal(&sharesies.CostSellRequest{
 		FundID:     "b8b7ef58-b270-4762-a256-9d68aebc3e23",
 		ActingAsID: "USER_ID",
 		Order: &sharesies.OrderSell{
-			Type:           sharesies.OrderTypeShareMarket, ShareAmount: "0.001000",
+			Type:        sharesies.OrderTypeShareMarket,
+			ShareAmount: &shares,
 		},
 	})
 
@@ -204,13 +289,71 @@ func Test_CostSell(t *testing.T) {
 	ctx := context.Background()
 	s.Authenticate(ctx, &sharesies.Credentials{Username: "username", Password: "password"})
 
-	i, err := s.CostSell(ctx, "b8b7ef58-b270-4762-a256-9d68aebc3e23", 0.001)
+	i, err := s.CostSell(ctx, "b8b7ef58-b270-4762-a256-9d68aebc3e23", sharesies.MustParseShares("0.001"))
 	mockClient.AssertExpectations(t)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, i)
 }
 
+func Test_CostBuy_Unauthorized(t *testing.T) {
+	mockClient := &MockClient{}
+	authSuccess(mockClient)
+	reAuthSuccess(mockClient)
+
+	costBuyUrl, _ := url.Parse("https://app.sharesies.nz/api/order/cost-buy")
+	costBuyBody, _ := os.Open("testdata/costbuy.json")
+
+	mockClient.On("Do", http.MethodPost, costBuyUrl, mock.Anything).Return(&http.Response{StatusCode: http.StatusUnauthorized}, nil).Once()
+	mockClient.On("Do", http.MethodPost, costBuyUrl, mock.Anything).Return(&http.Response{StatusCode: http.StatusOK, Body: costBuyBody}, nil).Once()
+
+	s := sharesies.Sharesies{
+		HttpClient: mockClient,
+	}
+
+	ctx := context.Background()
+	s.Authenticate(ctx, &sharesies.Credentials{Username: "username", Password: "password"})
+
+	i, err := s.CostBuy(ctx, "b8b7ef58-b270-4762-a256-9d68aebc3e23", sharesies.NewDecimal(10, 0))
+	mockClient.AssertExpectations(t)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "10", i.TotalCost.String())
+}
+
+func Test_CostBuy_ReAuthenticateFail(t *testing.T) {
+	mockClient := &MockClient{}
+	authSuccess(mockClient)
+
+	costBuyUrl, _ := url.Parse("https://app.sharesies.nz/api/order/cost-buy")
+	reAuthUrl, _ := url.Parse("https://app.sharesies.nz/api/identity/reauthenticate")
+
+	mockClient.On("Do", http.MethodPost, costBuyUrl, mock.Anything).Return(&http.Response{StatusCode: http.StatusUnauthorized}, nil)
+	mockClient.On("Do", http.MethodPost, reAuthUrl, mock.Anything).Return(&http.Response{StatusCode: http.StatusUnauthorized}, nil)
+
+	s := sharesies.Sharesies{
+		HttpClient: mockClient,
+	}
+
+	ctx := context.Background()
+	s.Authenticate(ctx, &sharesies.Credentials{Username: "username", Password: "password"})
+
+	_, err := s.CostBuy(ctx, "b8b7ef58-b270-4762-a256-9d68aebc3e23", sharesies.NewDecimal(10, 0))
+	mockClient.AssertExpectations(t)
+
+	assert.True(t, sharesies.IsUnauthorized(err))
+}
+
+func Test_CostBuy_NotAuthenticated(t *testing.T) {
+	s := sharesies.Sharesies{
+		HttpClient: &MockClient{},
+	}
+
+	_, err := s.CostBuy(context.Background(), "b8b7ef58-b270-4762-a256-9d68aebc3e23", sharesies.NewDecimal(10, 0))
+
+	assert.Equal(t, sharesies.ErrNotAuthenticated, err)
+}
+
 func reAuthSuccess(mockClient *MockClient) {
 	reAuthUrl, _ := url.Parse("https://app.sharesies.nz/api/identity/reauthenticate")
 	authBody, _ := os.Open("testdata/authenticated.json")
@@ -231,3 +374,37 @@ func marshal(v interface{}) string {
 	b, _ := json.Marshal(v)
 	return string(b)
 }
+
+func Test_Orders_NoReAuthenticate(t *testing.T) {
+	srv, _, s := newFakeServer(t)
+	ctx := context.Background()
+
+	costBuy, _ := s.CostBuy(ctx, fakeFundID, sharesies.NewDecimal(50, 0))
+	s.Buy(ctx, costBuy)
+
+	costSell, _ := s.CostSell(ctx, fakeFundID, sharesies.MustParseShares("10"))
+	_, err := s.Sell(ctx, costSell)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, srv.Requests("/api/identity/reauthenticate"))
+}
+
+func Test_Orders_RefreshNearExpiry(t *testing.T) {
+	srv := sharesiestest.NewServer()
+	defer srv.Close()
+
+	srv.AddUser("username", "password")
+	srv.SetTokenTTL(30 * time.Second)
+
+	opts := srv.Options()
+	opts.RefreshSkew = time.Minute
+
+	ctx := context.Background()
+	s, _ := sharesies.NewWithOptions(opts)
+	s.Authenticate(ctx, &sharesies.Credentials{Username: "username", Password: "password"})
+
+	_, err := s.Instruments(ctx, &sharesies.InstrumentsRequest{Page: 1, Perpage: 10})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, srv.Requests("/api/identity/reauthenticate"))
+}
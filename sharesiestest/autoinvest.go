@@ -0,0 +1,209 @@
+package sharesiestest
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// AddPremadeOrder makes a premade autoinvest portfolio available to every account
+func (s *Server) AddPremadeOrder(o *sharesies.PremadeOrder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.premade[o.ID] = o
+}
+
+// Autoinvest returns a copy of the autoinvest order of the Account, nil when it has none
+func (a *Account) Autoinvest() *sharesies.AutoinvestOrder {
+	a.srv.mu.Lock()
+	defer a.srv.mu.Unlock()
+
+	if a.autoinvest == nil {
+		return nil
+	}
+
+	o := *a.autoinvest
+	return &o
+}
+
+func (s *Server) handleCreateAutoinvest(w http.ResponseWriter, r *http.Request) {
+	s.saveAutoinvest(w, r, true)
+}
+
+func (s *Server) handleUpdateAutoinvest(w http.ResponseWriter, r *http.Request) {
+	s.saveAutoinvest(w, r, false)
+}
+
+func (s *Server) saveAutoinvest(w http.ResponseWriter, r *http.Request, create bool) {
+	var body sharesies.AutoinvestRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	switch {
+	case create && a.autoinvest != nil:
+		writeError(w, http.StatusBadRequest, "autoinvest_exists", "account already has an autoinvest order")
+		return
+	case !create && a.autoinvest == nil:
+		writeError(w, http.StatusNotFound, sharesies.ErrorCodeNoAutoinvest, "account has no autoinvest order")
+		return
+	}
+
+	allocations, ok := s.allocations(w, &body)
+	if !ok {
+		return
+	}
+
+	state := sharesies.AutoinvestStateActive
+	if a.autoinvest != nil {
+		state = a.autoinvest.State
+	}
+
+	next := nextAutoinvest(sharesies.Today(), body.Interval)
+	if body.StartDate != nil {
+		next = *body.StartDate
+	}
+
+	a.autoinvest = &sharesies.AutoinvestOrder{
+		Allocations:    allocations,
+		Amount:         body.Amount,
+		Interval:       body.Interval,
+		NextDate:       next.String(),
+		PremadeOrderID: body.PremadeOrderID,
+		State:          state,
+	}
+
+	writeJSON(w, http.StatusOK, &sharesies.AutoinvestResponse{Type: "autoinvest_order", Order: a.autoinvest})
+}
+
+// allocations validates the request and returns the allocations it invests in
+func (s *Server) allocations(w http.ResponseWriter, body *sharesies.AutoinvestRequest) ([]sharesies.Allocations, bool) {
+	switch body.Interval {
+	case sharesies.AutoinvestIntervalWeekly, sharesies.AutoinvestIntervalFortnightly, sharesies.AutoinvestIntervalMonthly:
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_order", "unknown interval")
+		return nil, false
+	}
+
+	if body.Amount.Sign() <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid_order", "amount must be positive")
+		return nil, false
+	}
+
+	if body.PremadeOrderID != "" {
+		p, ok := s.premade[body.PremadeOrderID]
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid_order", "premade order not found")
+			return nil, false
+		}
+
+		return p.Allocations, true
+	}
+
+	total := sharesies.Decimal{}
+	for _, a := range body.Allocations {
+		if _, ok := s.instruments[a.FundID]; !ok {
+			writeError(w, http.StatusBadRequest, sharesies.ErrorCodeInvalidFund, "fund not found")
+			return nil, false
+		}
+		total = total.Add(a.Allocation)
+	}
+
+	if len(body.Allocations) == 0 || !total.Equal(sharesies.NewDecimal(100, 0)) {
+		writeError(w, http.StatusBadRequest, "invalid_order", "allocations must add up to 100")
+		return nil, false
+	}
+
+	return body.Allocations, true
+}
+
+func (s *Server) handlePauseAutoinvest(w http.ResponseWriter, r *http.Request) {
+	s.autoinvestAction(w, r, func(a *Account) {
+		a.autoinvest.State = sharesies.AutoinvestStatePaused
+	})
+}
+
+func (s *Server) handleResumeAutoinvest(w http.ResponseWriter, r *http.Request) {
+	s.autoinvestAction(w, r, func(a *Account) {
+		a.autoinvest.State = sharesies.AutoinvestStateActive
+
+		today := sharesies.Today()
+		if next, err := sharesies.ParseDate(a.autoinvest.NextDate); err != nil || next.Before(today) {
+			a.autoinvest.NextDate = nextAutoinvest(today, a.autoinvest.Interval).String()
+		}
+	})
+}
+
+func (s *Server) handleDeleteAutoinvest(w http.ResponseWriter, r *http.Request) {
+	s.autoinvestAction(w, r, func(a *Account) {
+		a.autoinvest = nil
+	})
+}
+
+func (s *Server) autoinvestAction(w http.ResponseWriter, r *http.Request, action func(a *Account)) {
+	var body sharesies.AutoinvestActionRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	if a.autoinvest == nil {
+		writeError(w, http.StatusNotFound, sharesies.ErrorCodeNoAutoinvest, "account has no autoinvest order")
+		return
+	}
+
+	action(a)
+	writeJSON(w, http.StatusOK, &sharesies.AutoinvestResponse{Type: "autoinvest_order", Order: a.autoinvest})
+}
+
+func (s *Server) handlePremadeAutoinvest(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.AutoinvestActionRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, _, ok := s.actingAs(w, r, body.ActingAsID); !ok {
+		return
+	}
+
+	orders := make([]*sharesies.PremadeOrder, 0, len(s.premade))
+	for _, o := range s.premade {
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].Name < orders[j].Name })
+
+	writeJSON(w, http.StatusOK, &sharesies.PremadeOrdersResponse{Type: "premade_orders", Orders: orders})
+}
+
+// nextAutoinvest returns the day an order with interval next runs after today
+func nextAutoinvest(today sharesies.Date, interval string) sharesies.Date {
+	switch interval {
+	case sharesies.AutoinvestIntervalWeekly:
+		return today.AddDays(7)
+	case sharesies.AutoinvestIntervalFortnightly:
+		return today.AddDays(14)
+	default:
+		return sharesies.NewDate(today.In(time.UTC).AddDate(0, 1, 0))
+	}
+}
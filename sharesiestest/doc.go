@@ -0,0 +1,14 @@
+// Package sharesiestest provides an in-process fake of the Sharesies API
+// for integration tests.
+//
+// The fake keeps users, wallets and holdings in memory so multi-step flows
+// such as buy-then-sell can be exercised offline:
+//
+//	srv := sharesiestest.NewServer()
+//	defer srv.Close()
+//
+//	acc := srv.AddUser("email@example.com", "password")
+//	acc.Deposit("nzd", sharesies.NewDecimal(100, 0))
+//
+//	s, _ := sharesies.NewWithOptions(srv.Options())
+package sharesiestest
@@ -0,0 +1,129 @@
+package sharesiestest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// ratePlaces is the precision exchange rates are quoted to
+const ratePlaces = 6
+
+// SetExchangeRate sets how much of currency to one unit of from buys, the
+// reverse rate is set to its inverse
+func (s *Server) SetExchangeRate(from, to string, rate sharesies.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setRate(strings.ToLower(from), strings.ToLower(to), rate)
+}
+
+func (s *Server) setRate(from, to string, rate sharesies.Decimal) {
+	s.rates[from+to] = rate
+	s.rates[to+from] = sharesies.NewDecimal(1, 0).Div(rate, ratePlaces)
+}
+
+// quoteExchange prices converting amount of from into to, the brokerage fee
+// rate is charged on the converted amount
+func (s *Server) quoteExchange(w http.ResponseWriter, a *Account, from, to string, amount sharesies.Decimal) (*sharesies.CostExchangeResponse, bool) {
+	rate, ok := s.rates[from+to]
+	if !ok || from == to {
+		writeError(w, http.StatusBadRequest, "invalid_currency", "cannot exchange "+from+" into "+to)
+		return nil, false
+	}
+
+	if amount.Sign() <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid_exchange", "amount must be positive")
+		return nil, false
+	}
+
+	if a.wallet[from].LessThan(amount) {
+		writeError(w, http.StatusBadRequest, sharesies.ErrorCodeInsufficientFunds, "insufficient funds in "+from+" wallet")
+		return nil, false
+	}
+
+	converted := amount.Mul(rate).Round(2)
+	fee := s.fee(converted)
+
+	return &sharesies.CostExchangeResponse{
+		Type:           "exchange_cost",
+		SourceCurrency: from,
+		TargetCurrency: to,
+		SourceAmount:   amount,
+		TargetAmount:   converted.Sub(fee),
+		Rate:           rate,
+		Fee:            fee,
+	}, true
+}
+
+func (s *Server) handleCostExchange(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.CostExchangeRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	cost, ok := s.quoteExchange(w, a, body.SourceCurrency, body.TargetCurrency, body.SourceAmount)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cost)
+}
+
+func (s *Server) handleCreateExchange(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.CreateExchangeRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	if t, ok := a.keyed[body.IdempotencyKey]; ok {
+		writeJSON(w, http.StatusOK, &sharesies.ExchangeResponse{Type: "exchange", Transaction: t})
+		return
+	}
+
+	cost, ok := s.quoteExchange(w, a, body.SourceCurrency, body.TargetCurrency, body.SourceAmount)
+	if !ok {
+		return
+	}
+
+	if !cost.TargetAmount.Equal(body.ExpectedTargetAmount) {
+		writeError(w, http.StatusBadRequest, sharesies.ErrorCodeRateChanged, "exchange rate has changed since the quote")
+		return
+	}
+
+	a.wallet[cost.SourceCurrency] = a.wallet[cost.SourceCurrency].Sub(cost.SourceAmount)
+	a.wallet[cost.TargetCurrency] = a.wallet[cost.TargetCurrency].Add(cost.TargetAmount)
+
+	target, rate := cost.TargetAmount, cost.Rate
+	t := &sharesies.Transaction{
+		Type:             sharesies.TransactionTypeExchange,
+		Description:      "Exchanged " + strings.ToUpper(cost.SourceCurrency) + " to " + strings.ToUpper(cost.TargetCurrency),
+		Currency:         cost.SourceCurrency,
+		Amount:           cost.SourceAmount.Neg(),
+		Fee:              cost.Fee,
+		ExchangeCurrency: cost.TargetCurrency,
+		ExchangeAmount:   &target,
+		ExchangeRate:     &rate,
+	}
+	a.record(t)
+	a.keyed[body.IdempotencyKey] = t
+
+	writeJSON(w, http.StatusOK, &sharesies.ExchangeResponse{Type: "exchange", Transaction: t})
+}
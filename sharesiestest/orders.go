@@ -0,0 +1,332 @@
+package sharesiestest
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// order is an order placed by an Account, limit orders stay pending until
+// the market price reaches their limit
+type order struct {
+	id             string
+	fundID         string
+	side           string
+	orderType      string
+	currency       string
+	currencyAmount *sharesies.Decimal
+	shares         sharesies.Shares
+	priceLimit     *sharesies.Decimal
+	expiry         *sharesies.Date
+	state          string
+	// reserved is the amount held from the wallet for a buy
+	reserved     sharesies.Decimal
+	fillPrice    sharesies.Decimal
+	filledShares sharesies.Shares
+	filledAmount sharesies.Decimal
+	fee          sharesies.Decimal
+	created      time.Time
+	filled       time.Time
+}
+
+// limitOrder checks the fields a limit order requires on top of its share amount
+func limitOrder(w http.ResponseWriter, priceLimit *sharesies.Decimal, expiry *sharesies.Date) bool {
+	if priceLimit == nil || priceLimit.Sign() <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid_order", "price limit must be positive")
+		return false
+	}
+
+	if expiry == nil || expiry.Before(sharesies.Today()) {
+		writeError(w, http.StatusBadRequest, "invalid_order", "expiry date must not be in the past")
+		return false
+	}
+
+	return true
+}
+
+// placeBuy holds the total cost from the wallet and fills the order if the price allows
+func (s *Server) placeBuy(a *Account, fundID string, o *sharesies.OrderBuy, cost *sharesies.CostBuyResponse) *order {
+	currency := s.instruments[fundID].Currency()
+	a.wallet[currency] = a.wallet[currency].Sub(cost.TotalCost)
+
+	ord := &order{
+		id:             randomID(),
+		fundID:         fundID,
+		side:           sharesies.OrderSideBuy,
+		orderType:      o.Type,
+		currency:       currency,
+		currencyAmount: o.CurrencyAmount,
+		priceLimit:     o.PriceLimit,
+		expiry:         o.ExpiryDate,
+		reserved:       cost.TotalCost,
+	}
+
+	if o.ShareAmount != nil {
+		ord.shares = *o.ShareAmount
+	}
+
+	return s.place(a, ord)
+}
+
+// placeSell holds the shares from the holdings and fills the order if the price allows
+func (s *Server) placeSell(a *Account, fundID string, o *sharesies.OrderSell, shares sharesies.Shares) *order {
+	a.holdings[fundID] = a.holdings[fundID].Sub(shares)
+	if a.holdings[fundID].Sign() <= 0 {
+		delete(a.holdings, fundID)
+	}
+
+	return s.place(a, &order{
+		id:             randomID(),
+		fundID:         fundID,
+		side:           sharesies.OrderSideSell,
+		orderType:      o.Type,
+		currency:       s.instruments[fundID].Currency(),
+		currencyAmount: o.CurrencyAmount,
+		shares:         shares,
+		priceLimit:     o.PriceLimit,
+		expiry:         o.ExpiryDate,
+	})
+}
+
+func (s *Server) place(a *Account, o *order) *order {
+	o.state = sharesies.OrderStatePending
+	o.created = time.Now()
+	a.orders = append(a.orders, o)
+
+	s.match(a, o)
+	return o
+}
+
+// match fills a pending order when the market price reaches its limit, or
+// expires it and releases what it held once past its expiry date
+func (s *Server) match(a *Account, o *order) {
+	if o.state != sharesies.OrderStatePending && o.state != sharesies.OrderStateProcessing {
+		return
+	}
+
+	if time.Since(o.created) < s.fillDelay {
+		if o.priceLimit == nil {
+			o.state = sharesies.OrderStateProcessing
+		}
+		return
+	}
+
+	if o.expiry != nil && sharesies.Today().After(*o.expiry) {
+		s.release(a, o, sharesies.OrderStateExpired)
+		return
+	}
+
+	price := s.instruments[o.fundID].Marketprice
+	if o.priceLimit != nil {
+		if o.side == sharesies.OrderSideBuy && price.GreaterThan(*o.priceLimit) {
+			return
+		}
+
+		if o.side == sharesies.OrderSideSell && price.LessThan(*o.priceLimit) {
+			return
+		}
+	}
+
+	switch {
+	case o.side == sharesies.OrderSideBuy && o.orderType == sharesies.OrderTypeDollarMarket:
+		o.fee = s.fee(o.reserved)
+		o.filledAmount = o.reserved.Sub(o.fee)
+		o.filledShares = sharesies.Shares(o.filledAmount.Div(price, sharesies.SharesPlaces))
+	case o.side == sharesies.OrderSideBuy:
+		o.filledShares = o.shares
+		o.filledAmount = o.shares.Value(price).Round(2)
+		o.fee = s.fee(o.filledAmount)
+
+		refund := o.reserved.Sub(o.filledAmount).Sub(o.fee)
+		a.wallet[o.currency] = a.wallet[o.currency].Add(refund)
+	default:
+		o.filledShares = o.shares
+		o.filledAmount = o.shares.Value(price).Round(2)
+		o.fee = s.fee(o.filledAmount)
+
+		a.wallet[o.currency] = a.wallet[o.currency].Add(o.filledAmount.Sub(o.fee))
+	}
+
+	if o.side == sharesies.OrderSideBuy {
+		a.holdings[o.fundID] = a.holdings[o.fundID].Add(o.filledShares)
+	}
+
+	o.fillPrice = price
+	o.state = sharesies.OrderStateFulfilled
+	o.filled = time.Now()
+	a.recordFill(o)
+}
+
+// release returns what a pending order held back to the Account
+func (s *Server) release(a *Account, o *order, state string) {
+	if o.side == sharesies.OrderSideBuy {
+		a.wallet[o.currency] = a.wallet[o.currency].Add(o.reserved)
+	} else {
+		a.holdings[o.fundID] = a.holdings[o.fundID].Add(o.shares)
+	}
+
+	o.state = state
+}
+
+// matchFund tries to fill every pending order for fundID
+func (s *Server) matchFund(fundID string) {
+	for _, a := range s.accounts {
+		for _, o := range a.orders {
+			if o.fundID == fundID {
+				s.match(a, o)
+			}
+		}
+	}
+}
+
+func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.OrdersRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	states := map[string]bool{}
+	for _, state := range body.States {
+		states[state] = true
+	}
+
+	orders := []*sharesies.Order{}
+	for _, o := range s.orders(a) {
+		if len(states) == 0 || states[o.State] {
+			orders = append(orders, o)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, &sharesies.OrdersResponse{Type: "orders", Orders: orders})
+}
+
+func (s *Server) handleOrder(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.OrderRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	o, ok := s.findOrder(w, a, body.OrderID)
+	if !ok {
+		return
+	}
+
+	s.match(a, o)
+	writeJSON(w, http.StatusOK, &sharesies.OrderResponse{Type: "order", Order: o.toOrder()})
+}
+
+func (s *Server) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.OrderRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	o, ok := s.findOrder(w, a, body.OrderID)
+	if !ok {
+		return
+	}
+
+	s.match(a, o)
+	if o.state != sharesies.OrderStatePending {
+		writeError(w, http.StatusBadRequest, sharesies.ErrorCodeOrderNotPending, "order is "+o.state)
+		return
+	}
+
+	s.release(a, o, sharesies.OrderStateCancelled)
+	writeJSON(w, http.StatusOK, &sharesies.OrderResponse{Type: "order", Order: o.toOrder()})
+}
+
+func (s *Server) findOrder(w http.ResponseWriter, a *Account, id string) (*order, bool) {
+	for _, o := range a.orders {
+		if o.id == id {
+			return o, true
+		}
+	}
+
+	writeError(w, http.StatusNotFound, sharesies.ErrorCodeOrderNotFound, "order not found")
+	return nil, false
+}
+
+// orders returns every order of the Account, newest first
+func (s *Server) orders(a *Account) []*sharesies.Order {
+	orders := make([]*sharesies.Order, 0, len(a.orders))
+	for _, o := range a.orders {
+		s.match(a, o)
+		orders = append(orders, o.toOrder())
+	}
+
+	sort.SliceStable(orders, func(i, j int) bool { return orders[i].Created.After(orders[j].Created) })
+	return orders
+}
+
+func (o *order) toOrder() *sharesies.Order {
+	r := &sharesies.Order{
+		ID:             o.id,
+		FundID:         o.fundID,
+		Side:           o.side,
+		Type:           o.orderType,
+		State:          o.state,
+		Currency:       o.currency,
+		CurrencyAmount: o.currencyAmount,
+		PriceLimit:     o.priceLimit,
+		ExpiryDate:     o.expiry,
+		FilledShares:   o.filledShares,
+		FilledAmount:   o.filledAmount,
+		Fee:            o.fee,
+		Created:        o.created,
+	}
+
+	if o.orderType != sharesies.OrderTypeDollarMarket {
+		shares := o.shares
+		r.ShareAmount = &shares
+	}
+
+	if o.state == sharesies.OrderStateFulfilled {
+		price, filled := o.fillPrice, o.filled
+		r.FillPrice = &price
+		r.Filled = &filled
+	}
+
+	return r
+}
+
+// PendingOrders returns how many orders of the Account are waiting to be filled
+func (a *Account) PendingOrders() int {
+	a.srv.mu.Lock()
+	defer a.srv.mu.Unlock()
+
+	n := 0
+	for _, o := range a.orders {
+		if o.state == sharesies.OrderStatePending || o.state == sharesies.OrderStateProcessing {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,841 @@
+package sharesiestest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+const (
+	sessionCookie = "sharesies_session"
+
+	// DefaultTokenTTL is how long an issued distill token stays valid
+	DefaultTokenTTL = time.Hour
+)
+
+// DefaultFeeRate is the brokerage charged on every order
+var DefaultFeeRate = sharesies.NewDecimal(5, 3)
+
+// Default exchange rates between wallet currencies, see SetExchangeRate
+var (
+	DefaultNZDUSDRate = sharesies.NewDecimal(6, 1)
+	DefaultNZDAUDRate = sharesies.NewDecimal(9, 1)
+	DefaultUSDAUDRate = sharesies.NewDecimal(15, 1)
+)
+
+// Server is a stateful fake of the Sharesies app and data APIs
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	feeRate     sharesies.Decimal
+	tokenTTL    time.Duration
+	fillDelay   time.Duration
+	nzxClosed   bool
+	nzxNextOpen time.Time
+	secret      []byte
+	users       map[string]*user
+	accounts    map[string]*Account
+	instruments map[string]*sharesies.Company
+	premade     map[string]*sharesies.PremadeOrder
+	rates       map[string]sharesies.Decimal
+	sessions    map[string]*user
+	faults      map[string]*fault
+	delays      map[string]time.Duration
+	requests    map[string]int
+}
+
+type fault struct {
+	status    int
+	remaining int
+}
+
+type user struct {
+	email    string
+	password string
+	totp     *sharesies.TOTP
+	accounts []*Account
+}
+
+// Account is an investor account held by the fake Server
+type Account struct {
+	ID            string
+	PreferredName string
+
+	srv      *Server
+	wallet   map[string]sharesies.Decimal
+	holdings map[string]sharesies.Shares
+	keys     map[string]bool
+	orders   []*order
+
+	autoinvest   *sharesies.AutoinvestOrder
+	transactions []*sharesies.Transaction
+	// keyed holds the transactions of withdrawals and exchanges by idempotency key
+	keyed map[string]*sharesies.Transaction
+}
+
+// NewServer starts and returns a new fake Sharesies Server, the caller
+// should call Close when finished
+func NewServer() *Server {
+	s := &Server{
+		feeRate:     DefaultFeeRate,
+		tokenTTL:    DefaultTokenTTL,
+		secret:      []byte(randomID()),
+		users:       map[string]*user{},
+		accounts:    map[string]*Account{},
+		instruments: map[string]*sharesies.Company{},
+		premade:     map[string]*sharesies.PremadeOrder{},
+		rates:       map[string]sharesies.Decimal{},
+		sessions:    map[string]*user{},
+		faults:      map[string]*fault{},
+		delays:      map[string]time.Duration{},
+		requests:    map[string]int{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/identity/login", s.handleLogin)
+	mux.HandleFunc("/api/identity/check", s.handleCheck)
+	mux.HandleFunc("/api/identity/reauthenticate", s.handleReAuthenticate)
+	mux.HandleFunc("/api/v1/instruments", s.handleInstruments)
+	mux.HandleFunc("/api/order/cost-buy", s.handleCostBuy)
+	mux.HandleFunc("/api/order/create-buy", s.handleCreateBuy)
+	mux.HandleFunc("/api/order/cost-sell", s.handleCostSell)
+	mux.HandleFunc("/api/order/create-sell", s.handleCreateSell)
+	mux.HandleFunc("/api/order/list", s.handleOrders)
+	mux.HandleFunc("/api/order/get", s.handleOrder)
+	mux.HandleFunc("/api/order/cancel", s.handleCancelOrder)
+	mux.HandleFunc("/api/accounting/transaction-history", s.handleTransactions)
+	mux.HandleFunc("/api/autoinvest/create", s.handleCreateAutoinvest)
+	mux.HandleFunc("/api/autoinvest/update", s.handleUpdateAutoinvest)
+	mux.HandleFunc("/api/autoinvest/pause", s.handlePauseAutoinvest)
+	mux.HandleFunc("/api/autoinvest/resume", s.handleResumeAutoinvest)
+	mux.HandleFunc("/api/autoinvest/delete", s.handleDeleteAutoinvest)
+	mux.HandleFunc("/api/autoinvest/premade-orders", s.handlePremadeAutoinvest)
+	mux.HandleFunc("/api/wallet/balances", s.handleWallet)
+	mux.HandleFunc("/api/wallet/deposit-reference", s.handleDepositReference)
+	mux.HandleFunc("/api/wallet/withdraw", s.handleWithdraw)
+	mux.HandleFunc("/api/fx/cost", s.handleCostExchange)
+	mux.HandleFunc("/api/fx/create", s.handleCreateExchange)
+
+	s.setRate("nzd", "usd", DefaultNZDUSDRate)
+	s.setRate("nzd", "aud", DefaultNZDAUDRate)
+	s.setRate("usd", "aud", DefaultUSDAUDRate)
+
+	s.Server = httptest.NewServer(s.intercept(mux))
+
+	return s
+}
+
+// SetFeeRate sets the brokerage charged on the value of every order
+func (s *Server) SetFeeRate(rate sharesies.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.feeRate = rate
+}
+
+// SetTokenTTL sets the lifetime of distill tokens issued from now on,
+// a negative ttl issues tokens that are already expired
+func (s *Server) SetTokenTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokenTTL = ttl
+}
+
+// SetFillDelay keeps orders processing for d after they are placed before
+// they can be filled, by default orders fill as soon as the price allows
+func (s *Server) SetFillDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fillDelay = d
+}
+
+// SetNZXOpen sets whether the NZX is open and when it next opens, it is open by default
+func (s *Server) SetNZXOpen(open bool, nextOpen time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nzxClosed = !open
+	s.nzxNextOpen = nextOpen
+}
+
+// Delay makes every request to path wait d before being processed
+func (s *Server) Delay(path string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.delays[path] = d
+}
+
+// Fail makes the next n requests to path respond with status without being processed
+func (s *Server) Fail(path string, status int, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults[path] = &fault{status: status, remaining: n}
+}
+
+// Requests returns how many requests were received for path
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[path]
+}
+
+func (s *Server) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path]++
+
+		delay := s.delays[r.URL.Path]
+
+		f, ok := s.faults[r.URL.Path]
+		if ok && f.remaining > 0 {
+			f.remaining--
+			s.mu.Unlock()
+
+			writeError(w, f.status, "injected_fault", http.StatusText(f.status))
+			return
+		}
+		s.mu.Unlock()
+
+		time.Sleep(delay)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Options returns sharesies.Options pointing both app and data APIs at the Server
+func (s *Server) Options() *sharesies.Options {
+	return &sharesies.Options{
+		AppURL:  s.URL,
+		DataURL: s.URL,
+	}
+}
+
+// AddUser registers a login and returns its primary Account
+func (s *Server) AddUser(email, password string) *Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := &user{email: email, password: password}
+	s.users[email] = u
+
+	return s.addAccount(u, email)
+}
+
+// AddAccount adds another account, such as a kids or joint account, the
+// login of email can act as
+func (s *Server) AddAccount(email, name string) (*Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[email]
+	if !ok {
+		return nil, fmt.Errorf("sharesiestest: unknown user %s", email)
+	}
+
+	return s.addAccount(u, name), nil
+}
+
+// EnableMFA requires logins of email to be completed with a TOTP code
+// derived from the base32 secret
+func (s *Server) EnableMFA(email, secret string) error {
+	totp, err := sharesies.NewTOTP(secret)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[email]
+	if !ok {
+		return fmt.Errorf("sharesiestest: unknown user %s", email)
+	}
+
+	u.totp = totp
+	return nil
+}
+
+func (s *Server) addAccount(u *user, name string) *Account {
+	a := &Account{
+		ID:            randomID(),
+		PreferredName: name,
+		srv:           s,
+		wallet:        map[string]sharesies.Decimal{},
+		holdings:      map[string]sharesies.Shares{},
+		keys:          map[string]bool{},
+		keyed:         map[string]*sharesies.Transaction{},
+	}
+
+	u.accounts = append(u.accounts, a)
+	s.accounts[a.ID] = a
+
+	return a
+}
+
+// AddInstrument lists a Company/Fund so it can be searched and traded
+func (s *Server) AddInstrument(c *sharesies.Company) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.instruments[c.ID] = c
+}
+
+// SetPrice updates the market price of a listed instrument, filling the
+// limit orders the new price reaches
+func (s *Server) SetPrice(fundID string, price sharesies.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.instruments[fundID]; ok {
+		c.Marketprice = price
+		s.matchFund(fundID)
+	}
+}
+
+// Deposit credits the Account wallet
+func (a *Account) Deposit(currency string, amount sharesies.Decimal) {
+	a.srv.mu.Lock()
+	defer a.srv.mu.Unlock()
+
+	a.wallet[currency] = a.wallet[currency].Add(amount)
+	a.record(&sharesies.Transaction{
+		Type:        sharesies.TransactionTypeDeposit,
+		Description: "Deposit",
+		Currency:    currency,
+		Amount:      amount,
+	})
+}
+
+// Balance returns the Account wallet balance for currency
+func (a *Account) Balance(currency string) sharesies.Decimal {
+	a.srv.mu.Lock()
+	defer a.srv.mu.Unlock()
+
+	return a.wallet[currency]
+}
+
+// Shares returns the number of shares the Account holds of fundID
+func (a *Account) Shares(fundID string) sharesies.Shares {
+	a.srv.mu.Lock()
+	defer a.srv.mu.Unlock()
+
+	return a.holdings[fundID]
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		MFAToken string `json:"mfa_token"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[body.Email]
+	if !ok || u.password != body.Password {
+		writeJSON(w, http.StatusOK, &sharesies.ProfileResponse{Type: "identity_authentication_failed"})
+		return
+	}
+
+	if u.totp != nil && body.MFAToken == "" {
+		writeJSON(w, http.StatusOK, &sharesies.ProfileResponse{Type: sharesies.ResponseTypeMFARequired})
+		return
+	}
+
+	if u.totp != nil && !validCode(u.totp, body.MFAToken) {
+		writeJSON(w, http.StatusOK, &sharesies.ProfileResponse{Type: "identity_mfa_invalid"})
+		return
+	}
+
+	id := randomID()
+	s.sessions[id] = u
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: id, Path: "/", HttpOnly: true})
+
+	writeJSON(w, http.StatusOK, s.profile(u, u.accounts[0]))
+}
+
+func (s *Server) handleCheck(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.session(r)
+	if u == nil {
+		writeJSON(w, http.StatusOK, &sharesies.ProfileResponse{Type: "identity_check_fail"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.profile(u, u.accounts[0]))
+}
+
+func (s *Server) handleReAuthenticate(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Password   string `json:"password"`
+		ActingAsID string `json:"acting_as_id"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	if u.password != body.Password {
+		writeJSON(w, http.StatusOK, &sharesies.ProfileResponse{Type: "identity_authentication_failed"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.profile(u, a))
+}
+
+func (s *Server) handleInstruments(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.InstrumentsRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	if !s.validToken(r) {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "invalid distill token")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := map[string]bool{}
+	for _, id := range body.Instruments {
+		ids[id] = true
+	}
+
+	query := strings.ToLower(body.Query)
+	matches := []*sharesies.Company{}
+	for _, c := range s.instruments {
+		if len(ids) > 0 && !ids[c.ID] {
+			continue
+		}
+
+		if query != "" && !strings.Contains(strings.ToLower(c.Name), query) && !strings.Contains(strings.ToLower(c.Symbol), query) {
+			continue
+		}
+
+		matches = append(matches, c)
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+
+	perPage := body.Perpage
+	if perPage <= 0 {
+		perPage = len(matches)
+	}
+
+	page := body.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	pages := 1
+	if perPage > 0 {
+		pages = (len(matches) + perPage - 1) / perPage
+	}
+
+	start := (page - 1) * perPage
+	if start > len(matches) {
+		start = len(matches)
+	}
+
+	end := start + perPage
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	writeJSON(w, http.StatusOK, &sharesies.InstrumentResponse{
+		Total:          len(matches),
+		Currentpage:    page,
+		Resultsperpage: perPage,
+		Numberofpages:  pages,
+		Instruments:    matches[start:end],
+	})
+}
+
+func (s *Server) handleCostBuy(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.CostBuyRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	cost, ok := s.costBuy(w, a, body.FundID, body.Order)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cost)
+}
+
+func (s *Server) handleCreateBuy(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.CreateBuyRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	if a.keys[body.IdempotencyKey] {
+		writeJSON(w, http.StatusOK, s.profile(u, a))
+		return
+	}
+
+	cost, ok := s.costBuy(w, a, body.FundID, body.Order)
+	if !ok {
+		return
+	}
+
+	s.placeBuy(a, body.FundID, body.Order, cost)
+	a.keys[body.IdempotencyKey] = true
+
+	writeJSON(w, http.StatusOK, s.profile(u, a))
+}
+
+func (s *Server) handleCostSell(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.CostSellRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	if _, ok := s.sellShares(w, a, body.FundID, body.Order); !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &sharesies.CostSellResponse{
+		FundID:  body.FundID,
+		Request: body.Order,
+		Type:    "order_cost_sell",
+	})
+}
+
+func (s *Server) handleCreateSell(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.CreateSellRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	if a.keys[body.IdempotencyKey] {
+		writeJSON(w, http.StatusOK, s.profile(u, a))
+		return
+	}
+
+	shares, ok := s.sellShares(w, a, body.FundID, body.Order)
+	if !ok {
+		return
+	}
+
+	s.placeSell(a, body.FundID, body.Order, shares)
+	a.keys[body.IdempotencyKey] = true
+
+	writeJSON(w, http.StatusOK, s.profile(u, a))
+}
+
+func (s *Server) costBuy(w http.ResponseWriter, a *Account, fundID string, o *sharesies.OrderBuy) (*sharesies.CostBuyResponse, bool) {
+	c, ok := s.instruments[fundID]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid_fund", "fund not found")
+		return nil, false
+	}
+
+	var amount, fee, total sharesies.Decimal
+	switch {
+	case o == nil:
+		writeError(w, http.StatusBadRequest, "invalid_order", "order is required")
+		return nil, false
+	case o.Type == sharesies.OrderTypeDollarMarket:
+		if o.CurrencyAmount == nil || o.CurrencyAmount.Sign() <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid_order", "amount must be positive")
+			return nil, false
+		}
+
+		amount = o.CurrencyAmount.Round(2)
+		fee, total = s.fee(amount), amount
+	case o.Type == sharesies.OrderTypeShareMarket || o.Type == sharesies.OrderTypeShareLimit:
+		if o.ShareAmount == nil || o.ShareAmount.Sign() <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid_order", "share amount must be positive")
+			return nil, false
+		}
+
+		price := c.Marketprice
+		if o.Type == sharesies.OrderTypeShareLimit {
+			if !limitOrder(w, o.PriceLimit, o.ExpiryDate) {
+				return nil, false
+			}
+
+			price = *o.PriceLimit
+		}
+
+		amount = o.ShareAmount.Value(price).Round(2)
+		fee = s.fee(amount)
+		total = amount.Add(fee)
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_order", "unsupported order type")
+		return nil, false
+	}
+
+	currency := c.Currency()
+	if a.wallet[currency].LessThan(total) {
+		writeError(w, http.StatusBadRequest, "insufficient_funds", "wallet balance is too low")
+		return nil, false
+	}
+
+	return &sharesies.CostBuyResponse{
+		ExpectedFee: fee,
+		FundID:      fundID,
+		PaymentBreakdown: []*sharesies.PaymentBreakdown{
+			{Currency: currency, TargetAmount: total, Type: sharesies.PaymentType},
+		},
+		Request:   o,
+		TotalCost: total,
+		Type:      "order_cost_buy",
+	}, true
+}
+
+func (s *Server) sellShares(w http.ResponseWriter, a *Account, fundID string, o *sharesies.OrderSell) (sharesies.Shares, bool) {
+	c, ok := s.instruments[fundID]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid_fund", "fund not found")
+		return sharesies.Shares{}, false
+	}
+
+	var shares sharesies.Shares
+	switch {
+	case o == nil:
+		writeError(w, http.StatusBadRequest, "invalid_order", "order is required")
+		return sharesies.Shares{}, false
+	case o.Type == sharesies.OrderTypeDollarMarket:
+		if o.CurrencyAmount == nil || o.CurrencyAmount.Sign() <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid_order", "amount must be positive")
+			return sharesies.Shares{}, false
+		}
+
+		shares = sharesies.Shares(o.CurrencyAmount.Div(c.Marketprice, 2*sharesies.SharesPlaces).Truncate(sharesies.SharesPlaces))
+	case o.Type == sharesies.OrderTypeShareMarket || o.Type == sharesies.OrderTypeShareLimit:
+		if o.ShareAmount == nil || o.ShareAmount.Sign() <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid_order", "share amount must be positive")
+			return sharesies.Shares{}, false
+		}
+
+		if o.Type == sharesies.OrderTypeShareLimit && !limitOrder(w, o.PriceLimit, o.ExpiryDate) {
+			return sharesies.Shares{}, false
+		}
+
+		shares = *o.ShareAmount
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_order", "unsupported order type")
+		return sharesies.Shares{}, false
+	}
+
+	if a.holdings[fundID].Cmp(shares) < 0 {
+		writeError(w, http.StatusBadRequest, "insufficient_shares", "not enough shares held")
+		return sharesies.Shares{}, false
+	}
+
+	return shares, true
+}
+
+func (s *Server) fee(value sharesies.Decimal) sharesies.Decimal {
+	return value.Mul(s.feeRate).Round(2)
+}
+
+func (s *Server) session(r *http.Request) *user {
+	c, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil
+	}
+
+	return s.sessions[c.Value]
+}
+
+func (s *Server) actingAs(w http.ResponseWriter, r *http.Request, accountID string) (*user, *Account, bool) {
+	u := s.session(r)
+	if u == nil {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "not logged in")
+		return nil, nil, false
+	}
+
+	for _, a := range u.accounts {
+		if a.ID == accountID {
+			return u, a, true
+		}
+	}
+
+	writeError(w, http.StatusForbidden, "forbidden", "cannot act as this account")
+	return nil, nil, false
+}
+
+func (s *Server) validToken(r *http.Request) bool {
+	raw := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	_, err := jwt.ParseWithClaims(raw, &jwt.StandardClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	})
+
+	return err == nil
+}
+
+func (s *Server) token() string {
+	now := time.Now()
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.StandardClaims{
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.tokenTTL).Unix(),
+	})
+
+	raw, _ := t.SignedString(s.secret)
+	return raw
+}
+
+func (s *Server) profile(u *user, a *Account) *sharesies.ProfileResponse {
+	users := make([]*sharesies.UserSimple, 0, len(u.accounts))
+	for i, acc := range u.accounts {
+		users = append(users, &sharesies.UserSimple{
+			ID:            acc.ID,
+			PreferredName: acc.PreferredName,
+			Primary:       i == 0,
+			State:         "active",
+		})
+	}
+
+	portfolio := []*sharesies.Portfolio{}
+	for fundID, shares := range a.holdings {
+		c := s.instruments[fundID]
+		portfolio = append(portfolio, &sharesies.Portfolio{
+			Currency: c.Currency(),
+			FundID:   fundID,
+			Shares:   shares.Round(sharesies.SharesPlaces),
+			Value:    shares.Value(c.Marketprice).Round(2),
+		})
+	}
+
+	sort.Slice(portfolio, func(i, j int) bool { return portfolio[i].FundID < portfolio[j].FundID })
+
+	var nextOpen *sharesies.NzxNextOpen
+	if !s.nzxNextOpen.IsZero() {
+		nextOpen = &sharesies.NzxNextOpen{Quantum: s.nzxNextOpen.UnixNano() / int64(time.Millisecond)}
+	}
+
+	return &sharesies.ProfileResponse{
+		Authenticated:   true,
+		AutoinvestOrder: a.autoinvest,
+		DistillToken:    s.token(),
+		NzxIsOpen:       !s.nzxClosed,
+		NzxNextOpen:     nextOpen,
+		Orders:          s.orders(a),
+		Portfolio:       portfolio,
+		Type:            "identity_authenticated",
+		User: &sharesies.User{
+			AccountReference:        a.reference(),
+			Email:                   u.email,
+			HoldingBalance:          a.holdingBalance(),
+			ID:                      a.ID,
+			MaximumWithdrawalAmount: a.wallet["nzd"].Round(2),
+			PreferredName:           a.PreferredName,
+			WalletBalances:          a.walletBalances(),
+		},
+		UserList: users,
+	}
+}
+
+// validCode accepts the current code and the ones next to it to allow for clock drift
+func validCode(totp *sharesies.TOTP, code string) bool {
+	now := time.Now()
+	for _, skew := range []time.Duration{0, -totp.Period, totp.Period} {
+		if want, err := totp.Generate(now.Add(skew)); err == nil && want == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", r.Method+" not allowed")
+		return false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]string{"type": "error", "code": code, "message": message})
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("sharesiestest: %v", err))
+	}
+
+	return hex.EncodeToString(b)
+}
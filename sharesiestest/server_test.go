@@ -0,0 +1,179 @@
+package sharesiestest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/sharesiestest"
+)
+
+const fundID = "b8b7ef58-b270-4762-a256-9d68aebc3e23"
+
+func newServer(t *testing.T) (*sharesiestest.Server, *sharesiestest.Account, *sharesies.Sharesies) {
+	srv := sharesiestest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddInstrument(&sharesies.Company{
+		ID:              fundID,
+		Symbol:          "AIR",
+		Name:            "Air New Zealand",
+		Marketprice:     sharesies.MustParseDecimal("2.00"),
+		Exchange:        "NZX",
+		Exchangecountry: "nzl",
+	})
+
+	acc := srv.AddUser("username", "password")
+	acc.Deposit("nzd", sharesies.NewDecimal(100, 0))
+
+	opts := srv.Options()
+	opts.RetryPolicy = &sharesies.ExponentialBackoff{MaxRetries: 2, BaseDelay: time.Millisecond}
+
+	s, err := sharesies.NewWithOptions(opts)
+	assert.Nil(t, err)
+
+	_, err = s.Authenticate(context.Background(), &sharesies.Credentials{Username: "username", Password: "password"})
+	assert.Nil(t, err)
+
+	return srv, acc, s
+}
+
+func Test_Authenticate_Fail(t *testing.T) {
+	srv := sharesiestest.NewServer()
+	defer srv.Close()
+
+	srv.AddUser("username", "password")
+
+	s, _ := sharesies.NewWithOptions(srv.Options())
+	_, err := s.Authenticate(context.Background(), &sharesies.Credentials{Username: "username", Password: "wrong"})
+
+	assert.Equal(t, sharesies.ErrAuthentication, err)
+}
+
+func Test_Instruments(t *testing.T) {
+	_, _, s := newServer(t)
+
+	i, err := s.Instruments(context.Background(), &sharesies.InstrumentsRequest{Page: 1, Perpage: 10, Query: "air"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, i.Total)
+	assert.Equal(t, "AIR", i.Instruments[0].Symbol)
+}
+
+func Test_BuyThenSell(t *testing.T) {
+	_, acc, s := newServer(t)
+	ctx := context.Background()
+
+	costBuy, err := s.CostBuy(ctx, fundID, sharesies.NewDecimal(50, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, "0.25", costBuy.ExpectedFee.String())
+
+	p, err := s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+	assert.Equal(t, "50.00", p.User.WalletBalances.Nzd.String())
+	assert.Len(t, p.Portfolio, 1)
+	assert.Equal(t, "24.875000", p.Portfolio[0].Shares.String())
+	assert.Equal(t, "24.875000", acc.Shares(fundID).String())
+
+	costSell, err := s.CostSell(ctx, fundID, sharesies.MustParseShares("10"))
+	assert.Nil(t, err)
+
+	p, err = s.Sell(ctx, costSell)
+	assert.Nil(t, err)
+	assert.Equal(t, "14.875000", p.Portfolio[0].Shares.String())
+	assert.Equal(t, "69.90", acc.Balance("nzd").String())
+}
+
+func Test_Buy_InsufficientFunds(t *testing.T) {
+	_, _, s := newServer(t)
+
+	_, err := s.CostBuy(context.Background(), fundID, sharesies.NewDecimal(500, 0))
+
+	assert.ErrorIs(t, err, sharesies.ErrHttpRequest)
+	assert.True(t, sharesies.IsInsufficientFunds(err))
+}
+
+func Test_Sell_InsufficientShares(t *testing.T) {
+	_, _, s := newServer(t)
+
+	_, err := s.CostSell(context.Background(), fundID, sharesies.MustParseShares("1"))
+
+	assert.ErrorIs(t, err, sharesies.ErrHttpRequest)
+	assert.True(t, sharesies.IsInsufficientShares(err))
+}
+
+func Test_BuyLimit_FilledWhenPriceDrops(t *testing.T) {
+	srv, acc, s := newServer(t)
+	ctx := context.Background()
+
+	cost, err := s.CostBuyLimit(ctx, &sharesies.LimitOrder{
+		FundID:     fundID,
+		Shares:     sharesies.MustParseShares("10"),
+		PriceLimit: sharesies.MustParseDecimal("1.80"),
+		GoodTill:   sharesies.Today().AddDays(7),
+		Exchange:   "NZX",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "18.09", cost.TotalCost.String())
+
+	_, err = s.Buy(ctx, cost)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, acc.PendingOrders())
+	assert.Equal(t, "81.91", acc.Balance("nzd").String())
+	assert.True(t, acc.Shares(fundID).IsZero())
+
+	srv.SetPrice(fundID, sharesies.MustParseDecimal("1.75"))
+
+	assert.Equal(t, 0, acc.PendingOrders())
+	assert.Equal(t, "10.000000", acc.Shares(fundID).String())
+	assert.Equal(t, "82.41", acc.Balance("nzd").StringFixed(2))
+}
+
+func Test_SellLimit_FilledWhenPriceRises(t *testing.T) {
+	srv, acc, s := newServer(t)
+	ctx := context.Background()
+
+	costBuy, _ := s.CostBuy(ctx, fundID, sharesies.NewDecimal(50, 0))
+	_, err := s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+
+	cost, err := s.CostSellLimit(ctx, &sharesies.LimitOrder{
+		FundID:     fundID,
+		Shares:     sharesies.MustParseShares("10"),
+		PriceLimit: sharesies.MustParseDecimal("2.50"),
+		GoodTill:   sharesies.Today(),
+		Exchange:   "NZX",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	_, err = s.Sell(ctx, cost)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, acc.PendingOrders())
+	assert.Equal(t, "14.875000", acc.Shares(fundID).String())
+
+	srv.SetPrice(fundID, sharesies.MustParseDecimal("2.60"))
+
+	assert.Equal(t, 0, acc.PendingOrders())
+	assert.Equal(t, "75.87", acc.Balance("nzd").StringFixed(2))
+}
+
+func Test_BuyLimit_Invalid(t *testing.T) {
+	_, _, s := newServer(t)
+
+	_, err := s.CostBuyLimit(context.Background(), &sharesies.LimitOrder{
+		FundID:     fundID,
+		Shares:     sharesies.MustParseShares("10"),
+		PriceLimit: sharesies.MustParseDecimal("1.805"),
+		GoodTill:   sharesies.Today(),
+		Exchange:   "NZX",
+	})
+
+	assert.ErrorIs(t, err, sharesies.ErrInvalidOrder)
+}
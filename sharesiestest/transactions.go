@@ -0,0 +1,129 @@
+package sharesiestest
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// defaultTransactionsLimit is the page size used when a request sets none
+const defaultTransactionsLimit = 50
+
+// AddTransaction records t in the Account history and applies its Amount,
+// and ExchangeAmount for an exchange, to the wallet. It is meant for records
+// the Server does not produce itself, such as dividends, fees and exchanges.
+// ID and Timestamp are set when empty.
+func (a *Account) AddTransaction(t *sharesies.Transaction) {
+	a.srv.mu.Lock()
+	defer a.srv.mu.Unlock()
+
+	a.wallet[t.Currency] = a.wallet[t.Currency].Add(t.Amount)
+	if t.ExchangeCurrency != "" && t.ExchangeAmount != nil {
+		a.wallet[t.ExchangeCurrency] = a.wallet[t.ExchangeCurrency].Add(*t.ExchangeAmount)
+	}
+
+	a.record(t)
+}
+
+// record appends t to the history with the wallet balance it left, the caller holds the lock
+func (a *Account) record(t *sharesies.Transaction) {
+	if t.ID == "" {
+		t.ID = randomID()
+	}
+
+	if t.Timestamp.IsZero() {
+		t.Timestamp = time.Now()
+	}
+
+	t.Balance = a.wallet[t.Currency].Round(2)
+	a.transactions = append(a.transactions, t)
+}
+
+// recordFill adds the buy or sell transaction of a filled order
+func (a *Account) recordFill(o *order) {
+	shares, price := o.filledShares, o.fillPrice
+	t := &sharesies.Transaction{
+		Type:     o.side,
+		Currency: o.currency,
+		FundID:   o.fundID,
+		OrderID:  o.id,
+		Shares:   &shares,
+		Price:    &price,
+		Fee:      o.fee,
+	}
+
+	if o.side == sharesies.OrderSideBuy {
+		t.Description = "Bought shares"
+		t.Amount = o.filledAmount.Add(o.fee).Neg()
+	} else {
+		t.Description = "Sold shares"
+		t.Amount = o.filledAmount.Sub(o.fee)
+	}
+
+	a.record(t)
+}
+
+func (s *Server) handleTransactions(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.TransactionsRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	types := map[string]bool{}
+	for _, t := range body.Types {
+		types[t] = true
+	}
+
+	matches := []*sharesies.Transaction{}
+	for i := len(a.transactions) - 1; i >= 0; i-- {
+		t := a.transactions[i]
+		day := sharesies.NewDate(t.Timestamp)
+
+		switch {
+		case body.From != nil && day.Before(*body.From):
+		case body.To != nil && day.After(*body.To):
+		case body.FundID != "" && t.FundID != body.FundID:
+		case len(types) > 0 && !types[t.Type]:
+		default:
+			matches = append(matches, t)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Timestamp.After(matches[j].Timestamp) })
+
+	start := 0
+	if body.Before != "" {
+		for i, t := range matches {
+			if t.ID == body.Before {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	limit := body.Limit
+	if limit <= 0 {
+		limit = defaultTransactionsLimit
+	}
+
+	end := start + limit
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	writeJSON(w, http.StatusOK, &sharesies.TransactionsResponse{
+		Type:         "transaction_history",
+		Transactions: matches[start:end],
+		HasMore:      end < len(matches),
+	})
+}
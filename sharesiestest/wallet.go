@@ -0,0 +1,131 @@
+package sharesiestest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+const (
+	// DepositPayee and DepositBankAccount are returned as the bank details to deposit into
+	DepositPayee       = "Sharesies Limited"
+	DepositBankAccount = "12-3456-7890123-00"
+)
+
+// reference is the bank deposit reference of the Account
+func (a *Account) reference() string {
+	return "SH" + strings.ToUpper(a.ID[:8])
+}
+
+// holdingBalance is the NZD reserved by buy orders not filled yet, the caller holds the lock
+func (a *Account) holdingBalance() sharesies.Decimal {
+	held := sharesies.Decimal{}
+	for _, o := range a.orders {
+		if o.side == sharesies.OrderSideBuy && o.currency == "nzd" && (o.state == sharesies.OrderStatePending || o.state == sharesies.OrderStateProcessing) {
+			held = held.Add(o.reserved)
+		}
+	}
+
+	return held.Round(2)
+}
+
+// walletBalances returns the balances of the Account, the caller holds the lock
+func (a *Account) walletBalances() *sharesies.WalletBalances {
+	return &sharesies.WalletBalances{
+		Aud: a.wallet["aud"].Round(2),
+		Nzd: a.wallet["nzd"].Round(2),
+		Usd: a.wallet["usd"].Round(2),
+	}
+}
+
+func (s *Server) handleWallet(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.WalletRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	for _, o := range a.orders {
+		s.match(a, o)
+	}
+
+	writeJSON(w, http.StatusOK, &sharesies.WalletResponse{
+		Type:                    "wallet",
+		WalletBalances:          a.walletBalances(),
+		HoldingBalance:          a.holdingBalance(),
+		MaximumWithdrawalAmount: a.wallet["nzd"].Round(2),
+		AccountReference:        a.reference(),
+	})
+}
+
+func (s *Server) handleDepositReference(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.WalletRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &sharesies.DepositReferenceResponse{
+		Type:        "deposit_reference",
+		Payee:       DepositPayee,
+		BankAccount: DepositBankAccount,
+		Reference:   a.reference(),
+	})
+}
+
+func (s *Server) handleWithdraw(w http.ResponseWriter, r *http.Request) {
+	var body sharesies.WithdrawRequest
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, a, ok := s.actingAs(w, r, body.ActingAsID)
+	if !ok {
+		return
+	}
+
+	if t, ok := a.keyed[body.IdempotencyKey]; ok {
+		writeJSON(w, http.StatusOK, &sharesies.WithdrawResponse{Type: "withdrawal", Transaction: t})
+		return
+	}
+
+	if body.Amount.Sign() <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid_withdrawal", "amount must be positive")
+		return
+	}
+
+	if a.wallet["nzd"].LessThan(body.Amount) {
+		writeError(w, http.StatusBadRequest, sharesies.ErrorCodeInsufficientFunds, "amount is more than the maximum withdrawal")
+		return
+	}
+
+	a.wallet["nzd"] = a.wallet["nzd"].Sub(body.Amount)
+	t := &sharesies.Transaction{
+		Type:        sharesies.TransactionTypeWithdrawal,
+		Description: "Withdrawal",
+		Currency:    "nzd",
+		Amount:      body.Amount.Neg(),
+	}
+	a.record(t)
+	a.keyed[body.IdempotencyKey] = t
+
+	writeJSON(w, http.StatusOK, &sharesies.WithdrawResponse{Type: "withdrawal", Transaction: t})
+}
@@ -0,0 +1,19 @@
+// Package tax computes New Zealand Foreign Investment Fund (FIF) income for
+// individual investors from their Sharesies transaction history.
+//
+// Calculate runs the $50,000 de minimis test and works out FIF income under
+// both the Fair Dividend Rate method, including the quick sale adjustment,
+// and the Comparative Value method for a tax year running 1 April to
+// 31 March, picking the lower of the two:
+//
+//	r, err := tax.Calculate(&tax.Input{
+//		Year:         tax.TaxYear(2022),
+//		Transactions: h.Transactions,
+//		Instruments:  h.Instruments,
+//		Opening:      openingPrices,
+//		Closing:      closingPrices,
+//		Rates:        sharesies.FixedRates{"usd": sharesies.MustParseDecimal("1.42")},
+//	})
+//
+// The results are an aid to filling in a tax return, not tax advice.
+package tax
@@ -0,0 +1,336 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+const (
+	// MethodExempt means the de minimis exemption applies and there is no FIF income
+	MethodExempt = "exempt"
+	// MethodFDR is the Fair Dividend Rate method
+	MethodFDR = "fdr"
+	// MethodCV is the Comparative Value method
+	MethodCV = "cv"
+)
+
+var (
+	// DeMinimisThreshold is the total cost of FIF interests an individual can hold without FIF income
+	DeMinimisThreshold = sharesies.NewDecimal(50000, 0)
+	// FairDividendRate is the share of the opening market value taxed under the FDR method
+	FairDividendRate = sharesies.NewDecimal(5, 2)
+)
+
+var ErrMissingPrice = errors.New("missing price")
+
+// Input is what Calculate needs to work out the FIF income of a tax year
+type Input struct {
+	Year TaxYear
+	// Transactions is the whole history up to the end of Year, from the first purchase
+	Transactions []*sharesies.Transaction
+	// Instruments maps fund IDs to their Company/Fund, to tell which are FIF interests
+	Instruments map[string]*sharesies.Company
+	// Opening and Closing are the market price per share, in the instrument
+	// currency, of every fund on 1 April and 31 March
+	Opening map[string]sharesies.Decimal
+	Closing map[string]sharesies.Decimal
+	Rates   sharesies.Rates
+	// Exempt reports whether an instrument is not a FIF interest, by default
+	// only New Zealand listed instruments are. Australian listed companies
+	// keeping a franking account are exempt too and can be added here.
+	Exempt func(c *sharesies.Company) bool
+	// FDROnly disables the Comparative Value method, which is only available
+	// to individuals and family trusts
+	FDROnly bool
+	// NoDeMinimis opts out of the de minimis exemption
+	NoDeMinimis bool
+}
+
+// Result is the FIF income of a tax year, amounts are in NZD rounded to cents
+type Result struct {
+	Year TaxYear
+	// PeakCost is the highest total cost of FIF interests held during the year
+	PeakCost sharesies.Decimal
+	// DeMinimis reports whether PeakCost stayed within DeMinimisThreshold
+	DeMinimis bool
+	FDR       sharesies.Decimal
+	CV        sharesies.Decimal
+	// Method is the method Income was worked out with
+	Method string
+	// Income is the FIF income to declare
+	Income   sharesies.Decimal
+	Holdings []*Holding
+}
+
+// Holding is the FIF calculation of a single fund
+type Holding struct {
+	FundID        string
+	OpeningShares sharesies.Shares
+	ClosingShares sharesies.Shares
+	OpeningValue  sharesies.Decimal
+	ClosingValue  sharesies.Decimal
+	// Purchases is the cost, fees included, of the shares bought in the year
+	Purchases sharesies.Decimal
+	// Sales is the proceeds, fees deducted, of the shares sold in the year
+	Sales sharesies.Decimal
+	// Dividends is the gross dividends received in the year
+	Dividends           sharesies.Decimal
+	QuickSaleAdjustment sharesies.Decimal
+	FDR                 sharesies.Decimal
+	// CV is the comparative value result, negative for a loss
+	CV sharesies.Decimal
+}
+
+// position is the shares held of a fund and their NZD cost
+type position struct {
+	shares sharesies.Shares
+	cost   sharesies.Decimal
+}
+
+// yearActivity accumulates what happened to a fund during the year
+type yearActivity struct {
+	opening      sharesies.Shares
+	peak         sharesies.Shares
+	bought       sharesies.Shares
+	sold         sharesies.Shares
+	purchases    sharesies.Decimal
+	sales        sharesies.Decimal
+	dividends    sharesies.Decimal
+	openingValue sharesies.Decimal
+}
+
+// Calculate works out the FIF income of in.Year
+func Calculate(in *Input) (*Result, error) {
+	exempt := in.Exempt
+	if exempt == nil {
+		exempt = func(c *sharesies.Company) bool { return c.Exchangecountry == "nzl" }
+	}
+
+	fif := func(fundID string) bool {
+		c, ok := in.Instruments[fundID]
+		return fundID != "" && (!ok || !exempt(c))
+	}
+
+	transactions := make([]*sharesies.Transaction, 0, len(in.Transactions))
+	for _, t := range in.Transactions {
+		if fif(t.FundID) && TaxYearOf(t.Timestamp) <= in.Year {
+			transactions = append(transactions, t)
+		}
+	}
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Timestamp.Before(transactions[j].Timestamp)
+	})
+
+	positions := map[string]*position{}
+	activity := map[string]*yearActivity{}
+	r := &Result{Year: in.Year}
+	started := false
+
+	start := func() error {
+		started = true
+		for fundID, p := range positions {
+			a := &yearActivity{opening: p.shares, peak: p.shares}
+			activity[fundID] = a
+
+			if p.shares.IsZero() {
+				continue
+			}
+
+			v, err := in.value(fundID, p.shares, in.Opening, in.Year.Start())
+			if err != nil {
+				return err
+			}
+			a.openingValue = v
+		}
+
+		r.PeakCost = totalCost(positions)
+		return nil
+	}
+
+	for _, t := range transactions {
+		if !started && TaxYearOf(t.Timestamp) == in.Year {
+			if err := start(); err != nil {
+				return nil, err
+			}
+		}
+
+		day := sharesies.NewDate(t.Timestamp.In(sharesies.NZ))
+		amount, err := sharesies.ToNZD(in.Rates, t.Amount, t.Currency, day)
+		if err != nil {
+			return nil, err
+		}
+
+		p, ok := positions[t.FundID]
+		if !ok {
+			p = &position{}
+			positions[t.FundID] = p
+		}
+
+		a := activity[t.FundID]
+		if started && a == nil {
+			a = &yearActivity{}
+			activity[t.FundID] = a
+		}
+
+		switch {
+		case t.Type == sharesies.TransactionTypeBuy && t.Shares != nil:
+			p.shares = p.shares.Add(*t.Shares)
+			p.cost = p.cost.Add(amount.Neg())
+
+			if a != nil {
+				a.bought = a.bought.Add(*t.Shares)
+				a.purchases = a.purchases.Add(amount.Neg())
+			}
+		case t.Type == sharesies.TransactionTypeSell && t.Shares != nil:
+			if p.shares.Sign() > 0 {
+				p.cost = p.cost.Sub(p.cost.Mul(t.Shares.Decimal()).Div(p.shares.Decimal(), 10))
+			}
+			p.shares = p.shares.Sub(*t.Shares)
+
+			if a != nil {
+				a.sold = a.sold.Add(*t.Shares)
+				a.sales = a.sales.Add(amount)
+			}
+		case t.Type == sharesies.TransactionTypeDividend:
+			if a != nil {
+				gross, err := sharesies.ToNZD(in.Rates, t.Amount.Add(t.TaxWithheld), t.Currency, day)
+				if err != nil {
+					return nil, err
+				}
+				a.dividends = a.dividends.Add(gross)
+			}
+		}
+
+		if started {
+			if a.peak.Cmp(p.shares) < 0 {
+				a.peak = p.shares
+			}
+			r.PeakCost = sharesies.MaxDecimal(r.PeakCost, totalCost(positions))
+		}
+	}
+
+	if !started {
+		if err := start(); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]string, 0, len(activity))
+	for fundID := range activity {
+		ids = append(ids, fundID)
+	}
+	sort.Strings(ids)
+
+	for _, fundID := range ids {
+		h, err := in.holding(fundID, positions[fundID], activity[fundID])
+		if err != nil {
+			return nil, err
+		}
+
+		if h.OpeningShares.IsZero() && h.ClosingShares.IsZero() && h.Purchases.IsZero() && h.Dividends.IsZero() {
+			continue
+		}
+
+		r.Holdings = append(r.Holdings, h)
+		r.FDR = r.FDR.Add(h.FDR)
+		r.CV = r.CV.Add(h.CV)
+	}
+
+	// Individuals cannot claim a comparative value loss
+	r.CV = sharesies.MaxDecimal(r.CV, sharesies.Decimal{})
+	r.PeakCost = r.PeakCost.Round(2)
+	r.DeMinimis = !in.NoDeMinimis && !r.PeakCost.GreaterThan(DeMinimisThreshold)
+
+	switch {
+	case r.DeMinimis:
+		r.Method, r.Income = MethodExempt, sharesies.NewDecimal(0, 2)
+	case !in.FDROnly && r.CV.LessThan(r.FDR):
+		r.Method, r.Income = MethodCV, r.CV
+	default:
+		r.Method, r.Income = MethodFDR, r.FDR
+	}
+
+	return r, nil
+}
+
+func (in *Input) holding(fundID string, p *position, a *yearActivity) (*Holding, error) {
+	h := &Holding{
+		FundID:        fundID,
+		OpeningShares: a.opening,
+		ClosingShares: p.shares,
+		OpeningValue:  a.openingValue,
+		Purchases:     a.purchases,
+		Sales:         a.sales,
+		Dividends:     a.dividends,
+	}
+
+	if !p.shares.IsZero() {
+		v, err := in.value(fundID, p.shares, in.Closing, in.Year.End())
+		if err != nil {
+			return nil, err
+		}
+		h.ClosingValue = v
+	}
+
+	h.QuickSaleAdjustment = quickSaleAdjustment(a, p.shares)
+	h.FDR = a.openingValue.Mul(FairDividendRate).Add(h.QuickSaleAdjustment)
+	h.CV = h.ClosingValue.Add(h.Sales).Add(h.Dividends).Sub(h.OpeningValue).Sub(h.Purchases)
+
+	h.OpeningValue = h.OpeningValue.Round(2)
+	h.ClosingValue = h.ClosingValue.Round(2)
+	h.Purchases = h.Purchases.Round(2)
+	h.Sales = h.Sales.Round(2)
+	h.Dividends = h.Dividends.Round(2)
+	h.QuickSaleAdjustment = h.QuickSaleAdjustment.Round(2)
+	h.FDR = h.FDR.Round(2)
+	h.CV = h.CV.Round(2)
+
+	return h, nil
+}
+
+// quickSaleAdjustment is the FDR income on shares bought and sold in the same
+// year: the lesser of the peak holding differential method and the actual
+// gain made on them, never negative
+func quickSaleAdjustment(a *yearActivity, closing sharesies.Shares) sharesies.Decimal {
+	quick := sharesies.MinDecimal(a.bought.Decimal(), a.sold.Decimal())
+	if quick.Sign() <= 0 {
+		return sharesies.Decimal{}
+	}
+
+	averageCost := a.purchases.Div(a.bought.Decimal(), 10)
+	averageSale := a.sales.Div(a.sold.Decimal(), 10)
+
+	differential := a.peak.Decimal().Sub(sharesies.MaxDecimal(a.opening.Decimal(), closing.Decimal()))
+	peakMethod := FairDividendRate.Mul(sharesies.MinDecimal(differential, quick)).Mul(averageCost)
+	gain := quick.Mul(averageSale.Sub(averageCost))
+
+	return sharesies.MaxDecimal(sharesies.MinDecimal(peakMethod, gain), sharesies.Decimal{})
+}
+
+// value returns the NZD market value of shares of fundID at the price in prices on a day
+func (in *Input) value(fundID string, shares sharesies.Shares, prices map[string]sharesies.Decimal, on sharesies.Date) (sharesies.Decimal, error) {
+	price, ok := prices[fundID]
+	if !ok {
+		return sharesies.Decimal{}, fmt.Errorf("%w: %s on %s", ErrMissingPrice, fundID, on)
+	}
+
+	currency := "nzd"
+	if c, ok := in.Instruments[fundID]; ok {
+		currency = c.Currency()
+	}
+
+	return sharesies.ToNZD(in.Rates, shares.Value(price), currency, on)
+}
+
+func totalCost(positions map[string]*position) sharesies.Decimal {
+	total := sharesies.Decimal{}
+	for _, p := range positions {
+		total = total.Add(p.cost)
+	}
+
+	return total
+}
@@ -0,0 +1,134 @@
+package tax_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/tax"
+)
+
+const (
+	usFund = "us-fund"
+	nzFund = "nz-fund"
+)
+
+func decimal(s string) sharesies.Decimal {
+	return sharesies.MustParseDecimal(s)
+}
+
+func trade(typ string, on time.Time, fundID, currency, amount, shares string) *sharesies.Transaction {
+	t := &sharesies.Transaction{Type: typ, Timestamp: on, FundID: fundID, Currency: currency, Amount: decimal(amount)}
+	if shares != "" {
+		s := sharesies.MustParseShares(shares)
+		t.Shares = &s
+	}
+
+	return t
+}
+
+func at(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func input() *tax.Input {
+	dividend := trade(sharesies.TransactionTypeDividend, at(2021, time.September, 1), usFund, "usd", "50", "")
+	dividend.TaxWithheld = decimal("7.50")
+
+	return &tax.Input{
+		Year: tax.TaxYear(2022),
+		Transactions: []*sharesies.Transaction{
+			trade(sharesies.TransactionTypeBuy, at(2021, time.January, 10), usFund, "usd", "-10000", "100"),
+			trade(sharesies.TransactionTypeBuy, at(2021, time.June, 1), usFund, "usd", "-2200", "20"),
+			trade(sharesies.TransactionTypeSell, at(2021, time.August, 1), usFund, "usd", "2400", "20"),
+			dividend,
+			trade(sharesies.TransactionTypeBuy, at(2021, time.May, 1), nzFund, "nzd", "-90000", "1000"),
+			trade(sharesies.TransactionTypeBuy, at(2022, time.May, 1), usFund, "usd", "-90000", "100"),
+		},
+		Instruments: map[string]*sharesies.Company{
+			usFund: {ID: usFund, Exchangecountry: "usa"},
+			nzFund: {ID: nzFund, Exchangecountry: "nzl"},
+		},
+		Opening: map[string]sharesies.Decimal{usFund: decimal("105")},
+		Closing: map[string]sharesies.Decimal{usFund: decimal("130")},
+		Rates:   sharesies.FixedRates{"usd": decimal("1.5")},
+	}
+}
+
+func Test_TaxYear(t *testing.T) {
+	y := tax.TaxYear(2022)
+
+	assert.Equal(t, "2021-04-01", y.Start().String())
+	assert.Equal(t, "2022-03-31", y.End().String())
+	assert.Equal(t, y, tax.TaxYearOf(time.Date(2021, time.March, 31, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, tax.TaxYear(2021), tax.TaxYearOf(time.Date(2021, time.March, 30, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, y.Contains(at(2022, time.January, 1)))
+}
+
+func Test_Calculate_DeMinimis(t *testing.T) {
+	r, err := tax.Calculate(input())
+
+	assert.Nil(t, err)
+	assert.True(t, r.DeMinimis)
+	assert.Equal(t, "18300.00", r.PeakCost.String())
+	assert.Equal(t, tax.MethodExempt, r.Method)
+	assert.True(t, r.Income.IsZero())
+}
+
+func Test_Calculate_FDR(t *testing.T) {
+	in := input()
+	in.NoDeMinimis = true
+
+	r, err := tax.Calculate(in)
+
+	assert.Nil(t, err)
+	assert.Len(t, r.Holdings, 1)
+
+	h := r.Holdings[0]
+	assert.Equal(t, usFund, h.FundID)
+	assert.Equal(t, "15750.00", h.OpeningValue.String())
+	assert.Equal(t, "19500.00", h.ClosingValue.String())
+	assert.Equal(t, "86.25", h.Dividends.String())
+	assert.Equal(t, "165.00", h.QuickSaleAdjustment.String())
+	assert.Equal(t, "952.50", h.FDR.String())
+	assert.Equal(t, "4136.25", h.CV.String())
+
+	assert.Equal(t, tax.MethodFDR, r.Method)
+	assert.Equal(t, "952.50", r.Income.String())
+}
+
+func Test_Calculate_CV(t *testing.T) {
+	in := input()
+	in.NoDeMinimis = true
+	in.Closing[usFund] = decimal("100")
+
+	r, err := tax.Calculate(in)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "-363.75", r.Holdings[0].CV.String())
+	assert.Equal(t, tax.MethodCV, r.Method)
+	assert.True(t, r.Income.IsZero(), "a comparative value loss is not deductible")
+
+	in.FDROnly = true
+	r, err = tax.Calculate(in)
+
+	assert.Nil(t, err)
+	assert.Equal(t, tax.MethodFDR, r.Method)
+	assert.Equal(t, "952.50", r.Income.String())
+}
+
+func Test_Calculate_Missing(t *testing.T) {
+	in := input()
+	delete(in.Opening, usFund)
+
+	_, err := tax.Calculate(in)
+	assert.ErrorIs(t, err, tax.ErrMissingPrice)
+
+	in = input()
+	in.Rates = sharesies.FixedRates{}
+
+	_, err = tax.Calculate(in)
+	assert.ErrorIs(t, err, sharesies.ErrMissingRate)
+}
@@ -0,0 +1,36 @@
+package tax
+
+import (
+	"time"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+// TaxYear is a New Zealand income year named after the year it ends in,
+// TaxYear(2022) runs from 1 April 2021 to 31 March 2022
+type TaxYear int
+
+// TaxYearOf returns the tax year t falls in, in New Zealand time
+func TaxYearOf(t time.Time) TaxYear {
+	d := sharesies.NewDate(t.In(sharesies.NZ))
+	if d.Month >= time.April {
+		return TaxYear(d.Year + 1)
+	}
+
+	return TaxYear(d.Year)
+}
+
+// Start returns 1 April, the first day of the year
+func (y TaxYear) Start() sharesies.Date {
+	return sharesies.Date{Year: int(y) - 1, Month: time.April, Day: 1}
+}
+
+// End returns 31 March, the last day of the year
+func (y TaxYear) End() sharesies.Date {
+	return sharesies.Date{Year: int(y), Month: time.March, Day: 31}
+}
+
+// Contains reports whether t falls in the year, in New Zealand time
+func (y TaxYear) Contains(t time.Time) bool {
+	return TaxYearOf(t) == y
+}
@@ -0,0 +1,91 @@
+package sharesies
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidTOTPSecret = errors.New("invalid TOTP secret")
+var ErrInvalidTOTPDigits = errors.New("TOTP codes must have 6 to 8 digits")
+
+// TOTPProvider supplies the current one-time code for multi-factor authentication
+type TOTPProvider interface {
+	Code(ctx context.Context) (string, error)
+}
+
+// TOTP derives RFC 6238 time-based one-time codes from a shared secret,
+// the same codes shown by authenticator apps
+type TOTP struct {
+	Secret []byte
+	Digits int
+	Period time.Duration
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// NewTOTP returns a TOTP for the base32 secret shown when enabling 2FA,
+// generating 6 digit codes every 30 seconds
+func NewTOTP(secret string) (*TOTP, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidTOTPSecret
+	}
+
+	return &TOTP{Secret: key, Digits: 6, Period: 30 * time.Second}, nil
+}
+
+// Code returns the code for the current time
+func (t *TOTP) Code(ctx context.Context) (string, error) {
+	now := time.Now
+	if t.Now != nil {
+		now = t.Now
+	}
+
+	return t.Generate(now())
+}
+
+// Generate returns the code valid at instant, a Period under a second falls
+// back to 30 seconds and Digits must be 6 to 8, or zero for 6
+func (t *TOTP) Generate(instant time.Time) (string, error) {
+	period := t.Period
+	if period < time.Second {
+		period = 30 * time.Second
+	}
+
+	digits := t.Digits
+	if digits == 0 {
+		digits = 6
+	}
+
+	if digits < 6 || digits > 8 {
+		return "", fmt.Errorf("%w: %d", ErrInvalidTOTPDigits, t.Digits)
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(instant.Unix()/int64(period/time.Second)))
+
+	mac := hmac.New(sha1.New, t.Secret)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, value%mod), nil
+}
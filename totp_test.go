@@ -0,0 +1,107 @@
+package sharesies_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/sharesiestest"
+)
+
+// base32 of the RFC 6238 SHA1 test secret "12345678901234567890"
+const totpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func Test_TOTP(t *testing.T) {
+	totp, err := sharesies.NewTOTP(totpSecret)
+	assert.Nil(t, err)
+
+	totp.Digits = 8
+
+	cases := map[int64]string{59: "94287082", 1111111109: "07081804", 20000000000: "65353130"}
+	for unix, want := range cases {
+		code, err := totp.Generate(time.Unix(unix, 0))
+		assert.Nil(t, err)
+		assert.Equal(t, want, code)
+	}
+}
+
+func Test_TOTP_SubSecondPeriod(t *testing.T) {
+	totp, err := sharesies.NewTOTP(totpSecret)
+	assert.Nil(t, err)
+
+	totp.Digits = 8
+	totp.Period = 500 * time.Millisecond
+
+	code, err := totp.Generate(time.Unix(59, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, "94287082", code)
+}
+
+func Test_TOTP_InvalidDigits(t *testing.T) {
+	totp, err := sharesies.NewTOTP(totpSecret)
+	assert.Nil(t, err)
+
+	for _, digits := range []int{-1, 5, 9, 10, 32} {
+		totp.Digits = digits
+
+		_, err := totp.Generate(time.Unix(59, 0))
+		assert.ErrorIs(t, err, sharesies.ErrInvalidTOTPDigits, digits)
+
+		_, err = totp.Code(context.Background())
+		assert.ErrorIs(t, err, sharesies.ErrInvalidTOTPDigits, digits)
+	}
+}
+
+func Test_NewTOTP_Invalid(t *testing.T) {
+	_, err := sharesies.NewTOTP("not base32!")
+
+	assert.Equal(t, sharesies.ErrInvalidTOTPSecret, err)
+}
+
+func Test_Authenticate_MFA(t *testing.T) {
+	srv := sharesiestest.NewServer()
+	defer srv.Close()
+
+	srv.AddUser("username", "password")
+	srv.EnableMFA("username", totpSecret)
+
+	ctx := context.Background()
+	s, _ := sharesies.NewWithOptions(srv.Options())
+
+	_, err := s.Authenticate(ctx, &sharesies.Credentials{Username: "username", Password: "password"})
+	assert.ErrorIs(t, err, sharesies.ErrMFARequired)
+
+	_, err = s.SubmitMFA(ctx, "invalid")
+	assert.Equal(t, sharesies.ErrAuthentication, err)
+
+	totp, _ := sharesies.NewTOTP(totpSecret)
+	code, _ := totp.Code(ctx)
+
+	p, err := s.SubmitMFA(ctx, code)
+	assert.Nil(t, err)
+	assert.True(t, p.Authenticated)
+
+	_, err = s.SubmitMFA(ctx, code)
+	assert.Equal(t, sharesies.ErrNoPendingMFA, err)
+}
+
+func Test_Authenticate_TOTPProvider(t *testing.T) {
+	srv := sharesiestest.NewServer()
+	defer srv.Close()
+
+	srv.AddUser("username", "password")
+	srv.EnableMFA("username", totpSecret)
+
+	totp, _ := sharesies.NewTOTP(totpSecret)
+	opts := srv.Options()
+	opts.TOTPProvider = totp
+
+	s, _ := sharesies.NewWithOptions(opts)
+	p, err := s.Authenticate(context.Background(), &sharesies.Credentials{Username: "username", Password: "password"})
+
+	assert.Nil(t, err)
+	assert.True(t, p.Authenticated)
+}
@@ -0,0 +1,138 @@
+package sharesies
+
+import (
+	"context"
+	"net/http"
+)
+
+// TransactionFilter narrows down the transactions returned, the zero value matches everything
+type TransactionFilter struct {
+	// From and To are the first and last day included, unbounded when zero
+	From   Date
+	To     Date
+	FundID string
+	// Types restricts the transactions to the TransactionType values listed
+	Types []string
+	// PerPage is the number of transactions fetched per request, the server decides when zero
+	PerPage int
+}
+
+// TransactionIterator walks the transactions matching a filter newest first,
+// fetching the next page when the current one is exhausted
+type TransactionIterator struct {
+	ctx    context.Context
+	a      *Account
+	filter *TransactionFilter
+
+	page    []*Transaction
+	current *Transaction
+	before  string
+	done    bool
+	err     error
+}
+
+// Transactions returns every transaction matching filter, newest first
+func (s *Sharesies) Transactions(ctx context.Context, filter *TransactionFilter) ([]*Transaction, error) {
+	return s.account().Transactions(ctx, filter)
+}
+
+// IterateTransactions returns an iterator over the transactions matching filter, newest first
+func (s *Sharesies) IterateTransactions(ctx context.Context, filter *TransactionFilter) *TransactionIterator {
+	return s.account().IterateTransactions(ctx, filter)
+}
+
+// TransactionsPage returns one page of the transactions matching filter
+// older than the transaction with id before, or the first page when before is empty
+func (s *Sharesies) TransactionsPage(ctx context.Context, filter *TransactionFilter, before string) (*TransactionsResponse, error) {
+	return s.account().TransactionsPage(ctx, filter, before)
+}
+
+// Transactions returns every transaction matching filter, newest first
+func (a *Account) Transactions(ctx context.Context, filter *TransactionFilter) ([]*Transaction, error) {
+	it := a.IterateTransactions(ctx, filter)
+
+	transactions := []*Transaction{}
+	for it.Next() {
+		transactions = append(transactions, it.Transaction())
+	}
+
+	return transactions, it.Err()
+}
+
+// IterateTransactions returns an iterator over the transactions matching filter, newest first
+func (a *Account) IterateTransactions(ctx context.Context, filter *TransactionFilter) *TransactionIterator {
+	if filter == nil {
+		filter = &TransactionFilter{}
+	}
+
+	return &TransactionIterator{ctx: ctx, a: a, filter: filter}
+}
+
+// TransactionsPage returns one page of the transactions matching filter
+// older than the transaction with id before, or the first page when before is empty
+func (a *Account) TransactionsPage(ctx context.Context, filter *TransactionFilter, before string) (*TransactionsResponse, error) {
+	if filter == nil {
+		filter = &TransactionFilter{}
+	}
+
+	actingAsID, err := a.actingAsID()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &TransactionsResponse{}
+	tr := &TransactionsRequest{
+		ActingAsID: actingAsID,
+		FundID:     filter.FundID,
+		Types:      filter.Types,
+		Before:     before,
+		Limit:      filter.PerPage,
+	}
+
+	if !filter.From.IsZero() {
+		tr.From = &filter.From
+	}
+
+	if !filter.To.IsZero() {
+		tr.To = &filter.To
+	}
+
+	err = a.s.authRequest(ctx, http.MethodPost, a.s.endpoints().Transactions, false, tr, r)
+	return r, err
+}
+
+// Next advances to the next transaction, it returns false when there are no
+// more transactions or a page could not be fetched, see Err
+func (it *TransactionIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			it.current = nil
+			return false
+		}
+
+		r, err := it.a.TransactionsPage(it.ctx, it.filter, it.before)
+		if err != nil {
+			it.err = err
+			continue
+		}
+
+		it.page = r.Transactions
+		it.done = !r.HasMore || len(r.Transactions) == 0
+		if len(r.Transactions) > 0 {
+			it.before = r.Transactions[len(r.Transactions)-1].ID
+		}
+	}
+
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Transaction returns the transaction Next advanced to
+func (it *TransactionIterator) Transaction() *Transaction {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *TransactionIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,65 @@
+package sharesies_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+func Test_Transactions(t *testing.T) {
+	_, acc, s := newFakeServer(t)
+	ctx := context.Background()
+
+	costBuy, _ := s.CostBuy(ctx, fakeFundID, sharesies.NewDecimal(50, 0))
+	_, err := s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+
+	acc.AddTransaction(&sharesies.Transaction{
+		Type:        sharesies.TransactionTypeDividend,
+		Timestamp:   time.Date(2021, time.June, 1, 12, 0, 0, 0, time.UTC),
+		Currency:    "nzd",
+		Amount:      sharesies.MustParseDecimal("1.20"),
+		FundID:      fakeFundID,
+		TaxWithheld: sharesies.MustParseDecimal("0.30"),
+	})
+
+	all, err := s.Transactions(ctx, &sharesies.TransactionFilter{PerPage: 1})
+	assert.Nil(t, err)
+	assert.Len(t, all, 3)
+	assert.Equal(t, sharesies.TransactionTypeBuy, all[0].Type)
+	assert.Equal(t, "-50.00", all[0].Amount.String())
+	assert.Equal(t, "24.875000", all[0].Shares.String())
+	assert.Equal(t, "0.25", all[0].Fee.String())
+	assert.Equal(t, sharesies.TransactionTypeDeposit, all[1].Type)
+	assert.Equal(t, sharesies.TransactionTypeDividend, all[2].Type)
+	assert.Equal(t, "51.20", all[2].Balance.String())
+
+	buys, err := s.Transactions(ctx, &sharesies.TransactionFilter{Types: []string{sharesies.TransactionTypeBuy}})
+	assert.Nil(t, err)
+	assert.Len(t, buys, 1)
+
+	june, err := s.Transactions(ctx, &sharesies.TransactionFilter{
+		From:   sharesies.Date{Year: 2021, Month: time.June, Day: 1},
+		To:     sharesies.Date{Year: 2021, Month: time.June, Day: 30},
+		FundID: fakeFundID,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, june, 1)
+	assert.Equal(t, "0.30", june[0].TaxWithheld.String())
+}
+
+func Test_IterateTransactions_Error(t *testing.T) {
+	srv, _, s := newFakeServer(t)
+	srv.Fail("/api/accounting/transaction-history", http.StatusBadRequest, 1)
+
+	it := s.IterateTransactions(context.Background(), nil)
+
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Err(), sharesies.ErrHttpRequest)
+	assert.Nil(t, it.Transaction())
+}
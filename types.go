@@ -1,13 +1,36 @@
 package sharesies
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 const (
 	OrderTypeDollarMarket = "dollar_market"
-	OrderTypeShareMarket = "share_market"
+	OrderTypeShareMarket  = "share_market"
+	OrderTypeShareLimit   = "share_limit"
+
+	OrderSideBuy  = "buy"
+	OrderSideSell = "sell"
+
+	OrderStatePending    = "pending"
+	OrderStateProcessing = "processing"
+	OrderStateFulfilled  = "fulfilled"
+	OrderStateCancelled  = "cancelled"
+	OrderStateRejected   = "rejected"
+	OrderStateExpired    = "expired"
 
-	PaymentCurrency = "nzd"
-	PaymentType     = "direct"
+	TransactionTypeBuy        = "buy"
+	TransactionTypeSell       = "sell"
+	TransactionTypeDividend   = "dividend"
+	TransactionTypeDeposit    = "deposit"
+	TransactionTypeWithdrawal = "withdrawal"
+	TransactionTypeFee        = "fee"
+	TransactionTypeExchange   = "exchange"
+
+	PaymentCurrency     = "nzd"
+	PaymentType         = "direct"
+	PaymentTypeExchange = "exchange"
 )
 
 type ProfileResponse struct {
@@ -21,7 +44,7 @@ type ProfileResponse struct {
 	LiveData                *LiveData        `json:"live_data" validate:"required"`
 	NzxIsOpen               bool             `json:"nzx_is_open" validate:"required"`
 	NzxNextOpen             *NzxNextOpen     `json:"nzx_next_open" validate:"required"`
-	Orders                  []interface{}    `json:"orders"`
+	Orders                  []*Order         `json:"orders"`
 	OutstandingSubscription interface{}      `json:"outstanding_subscription"`
 	Participants            []string         `json:"participants" validate:"required"`
 	Portfolio               []*Portfolio     `json:"portfolio" validate:"required"`
@@ -34,16 +57,16 @@ type ProfileResponse struct {
 	UserList                []*UserSimple    `json:"user_list" validate:"required"`
 }
 type Allocations struct {
-	Allocation string `json:"allocation" validate:"required"`
-	FundID     string `json:"fund_id" validate:"required"`
+	Allocation Decimal `json:"allocation" validate:"required"`
+	FundID     string  `json:"fund_id" validate:"required"`
 }
 type AutoinvestOrder struct {
 	Allocations    []Allocations `json:"allocations" validate:"required"`
-	Amount         string        `json:"amount" validate:"required"`
+	Amount         Decimal       `json:"amount" validate:"required"`
 	Interval       string        `json:"interval" validate:"required"`
 	LastFailedDate interface{}   `json:"last_failed_date"`
 	NextDate       string        `json:"next_date" validate:"required"`
-	PremadeOrderID interface{}   `json:"premade_order_id"`
+	PremadeOrderID string        `json:"premade_order_id"`
 	State          string        `json:"state" validate:"required"`
 }
 type CanWriteUntil struct {
@@ -56,32 +79,44 @@ type LiveData struct {
 type NzxNextOpen struct {
 	Quantum int64 `json:"$quantum" validate:"required"`
 }
+
+// Time returns when the NZX next opens, the quantum is in milliseconds since the Unix epoch
+func (n *NzxNextOpen) Time() time.Time {
+	return time.Unix(0, n.Quantum*int64(time.Millisecond))
+}
+
 type Stats struct {
-	CapitalReturn        string `json:"capital_return" validate:"required"`
-	SharesBought         string `json:"shares_bought" validate:"required"`
-	SharesSold           string `json:"shares_sold" validate:"required"`
-	SharesTransferredIn  string `json:"shares_transferred_in" validate:"required"`
-	SharesTransferredOut string `json:"shares_transferred_out" validate:"required"`
-	ValueBought          string `json:"value_bought" validate:"required"`
-	ValueSold            string `json:"value_sold" validate:"required"`
-	ValueTransferredIn   string `json:"value_transferred_in" validate:"required"`
-	ValueTransferredOut  string `json:"value_transferred_out" validate:"required"`
+	CapitalReturn        Decimal `json:"capital_return" validate:"required"`
+	SharesBought         Shares  `json:"shares_bought" validate:"required"`
+	SharesSold           Shares  `json:"shares_sold" validate:"required"`
+	SharesTransferredIn  Shares  `json:"shares_transferred_in" validate:"required"`
+	SharesTransferredOut Shares  `json:"shares_transferred_out" validate:"required"`
+	ValueBought          Decimal `json:"value_bought" validate:"required"`
+	ValueSold            Decimal `json:"value_sold" validate:"required"`
+	ValueTransferredIn   Decimal `json:"value_transferred_in" validate:"required"`
+	ValueTransferredOut  Decimal `json:"value_transferred_out" validate:"required"`
 }
 type Portfolio struct {
-	Contribution        string `json:"contribution" validate:"required"`
-	Currency            string `json:"currency" validate:"required"`
-	CurrentTaxLiability string `json:"current_tax_liability" validate:"required"`
-	Dividends           string `json:"dividends" validate:"required"`
-	FundID              string `json:"fund_id" validate:"required"`
-	GrossValue          string `json:"gross_value" validate:"required"`
-	HoldingType         string `json:"holding_type" validate:"required"`
-	ReturnDollars       string `json:"return_dollars" validate:"required"`
-	ReturnPercent       string `json:"return_percent" validate:"required"`
-	RiskRating          int    `json:"risk_rating" validate:"required"`
-	Shares              string `json:"shares" validate:"required"`
-	Stats               *Stats `json:"stats" validate:"required"`
-	Value               string `json:"value" validate:"required"`
+	Contribution        Decimal `json:"contribution" validate:"required"`
+	Currency            string  `json:"currency" validate:"required"`
+	CurrentTaxLiability Decimal `json:"current_tax_liability" validate:"required"`
+	Dividends           Decimal `json:"dividends" validate:"required"`
+	FundID              string  `json:"fund_id" validate:"required"`
+	GrossValue          Decimal `json:"gross_value" validate:"required"`
+	HoldingType         string  `json:"holding_type" validate:"required"`
+	ReturnDollars       Decimal `json:"return_dollars" validate:"required"`
+	ReturnPercent       Decimal `json:"return_percent" validate:"required"`
+	RiskRating          int     `json:"risk_rating" validate:"required"`
+	Shares              Shares  `json:"shares" validate:"required"`
+	Stats               *Stats  `json:"stats" validate:"required"`
+	Value               Decimal `json:"value" validate:"required"`
 }
+
+// MarketValue returns the value of the holding in its currency
+func (p *Portfolio) MarketValue() Money {
+	return NewMoney(p.Value, p.Currency)
+}
+
 type Components struct {
 	Locality     string `json:"locality" validate:"required"`
 	PostalCode   string `json:"postal_code" validate:"required"`
@@ -121,11 +156,29 @@ type TaxResidencies struct {
 	CountryName string `json:"country_name" validate:"required"`
 	Tin         string `json:"tin" validate:"required"`
 }
+
+// WalletBalances holds the wallet balance of each currency
 type WalletBalances struct {
-	Aud string `json:"aud" validate:"required"`
-	Nzd string `json:"nzd" validate:"required"`
-	Usd string `json:"usd" validate:"required"`
+	Aud Decimal `json:"aud" validate:"required"`
+	Nzd Decimal `json:"nzd" validate:"required"`
+	Usd Decimal `json:"usd" validate:"required"`
+}
+
+// Balance returns the wallet balance for currency ("nzd", "usd" or "aud"),
+// zero for any other currency
+func (w *WalletBalances) Balance(currency string) Money {
+	switch strings.ToLower(currency) {
+	case "aud":
+		return NewMoney(w.Aud, currency)
+	case "usd":
+		return NewMoney(w.Usd, currency)
+	case "nzd":
+		return NewMoney(w.Nzd, currency)
+	default:
+		return NewMoney(Decimal{}, currency)
+	}
 }
+
 type User struct {
 	AccountFrozen           bool              `json:"account_frozen" validate:"required"`
 	AccountReference        string            `json:"account_reference" validate:"required"`
@@ -138,7 +191,7 @@ type User struct {
 	Email                   string            `json:"email" validate:"required"`
 	FirstTaxYear            int               `json:"first_tax_year" validate:"required"`
 	HasSeen                 *HasSeen          `json:"has_seen" validate:"required"`
-	HoldingBalance          string            `json:"holding_balance" validate:"required"`
+	HoldingBalance          Decimal           `json:"holding_balance" validate:"required"`
 	HomeCurrency            string            `json:"home_currency" validate:"required"`
 	ID                      string            `json:"id" validate:"required"`
 	Intercom                string            `json:"intercom" validate:"required"`
@@ -146,11 +199,11 @@ type User struct {
 	IsDependent             bool              `json:"is_dependent" validate:"required"`
 	IsOwnerPrescribed       bool              `json:"is_owner_prescribed" validate:"required"`
 	Jurisdiction            string            `json:"jurisdiction" validate:"required"`
-	MaximumWithdrawalAmount string            `json:"maximum_withdrawal_amount" validate:"required"`
-	MinimumWalletBalance    string            `json:"minimum_wallet_balance" validate:"required"`
+	MaximumWithdrawalAmount Decimal           `json:"maximum_withdrawal_amount" validate:"required"`
+	MinimumWalletBalance    Decimal           `json:"minimum_wallet_balance" validate:"required"`
 	ParticipantEmails       []interface{}     `json:"participant_emails"`
 	Phone                   string            `json:"phone" validate:"required"`
-	Pir                     string            `json:"pir" validate:"required"`
+	Pir                     Decimal           `json:"pir" validate:"required"`
 	PortfolioID             string            `json:"portfolio_id" validate:"required"`
 	PreferredName           string            `json:"preferred_name" validate:"required"`
 	PrescribedApproved      bool              `json:"prescribed_approved" validate:"required"`
@@ -210,11 +263,11 @@ type Company struct {
 	Logoidentifier            string      `json:"logoIdentifier" validate:"required"`
 	Logos                     *Logos      `json:"logos" validate:"required"`
 	Riskrating                int         `json:"riskRating" validate:"required"`
-	Marketprice               string      `json:"marketPrice" validate:"required"`
+	Marketprice               Decimal     `json:"marketPrice" validate:"required"`
 	Marketlastcheck           time.Time   `json:"marketLastCheck" validate:"required"`
 	Tradingstatus             string      `json:"tradingStatus" validate:"required"`
 	Exchangecountry           string      `json:"exchangeCountry" validate:"required"`
-	Peratio                   string      `json:"peRatio" validate:"required"`
+	Peratio                   Decimal     `json:"peRatio" validate:"required"`
 	Marketcap                 int64       `json:"marketCap" validate:"required"`
 	Websiteurl                string      `json:"websiteUrl" validate:"required"`
 	Exchange                  string      `json:"exchange" validate:"required"`
@@ -224,12 +277,24 @@ type Company struct {
 	Assetmanager              interface{} `json:"assetManager"`
 	Fixedfeespread            interface{} `json:"fixedFeeSpread"`
 	Managementfeepercent      interface{} `json:"managementFeePercent"`
-	Grossdividendyieldpercent string      `json:"grossDividendYieldPercent" validate:"required"`
-	Annualisedreturnpercent   string      `json:"annualisedReturnPercent" validate:"required"`
+	Grossdividendyieldpercent Decimal     `json:"grossDividendYieldPercent" validate:"required"`
+	Annualisedreturnpercent   Decimal     `json:"annualisedReturnPercent" validate:"required"`
 	Ceo                       string      `json:"ceo" validate:"required"`
 	Employees                 int         `json:"employees" validate:"required"`
 }
 
+// Currency returns the currency the instrument trades in from its exchange country
+func (c *Company) Currency() string {
+	switch c.Exchangecountry {
+	case "usa":
+		return "usd"
+	case "aus":
+		return "aud"
+	default:
+		return "nzd"
+	}
+}
+
 // Buy Transactions Types
 
 type CostBuyRequest struct {
@@ -239,24 +304,35 @@ type CostBuyRequest struct {
 }
 
 type OrderBuy struct {
-	Type           string `json:"type" validate:"required"`
-	CurrencyAmount string `json:"currency_amount,omitempty"`
-	ShareAmount    string `json:"share_amount,omitempty"`
+	Type           string   `json:"type" validate:"required"`
+	CurrencyAmount *Decimal `json:"currency_amount,omitempty"`
+	ShareAmount    *Shares  `json:"share_amount,omitempty"`
+	PriceLimit     *Decimal `json:"price_limit,omitempty"`
+	ExpiryDate     *Date    `json:"expiry_date,omitempty"`
 }
 
 type PaymentBreakdown struct {
-	Currency     string `json:"currency" validate:"required"`
-	TargetAmount string `json:"target_amount" validate:"required"`
-	Type         string `json:"type" validate:"required"`
+	Currency     string  `json:"currency" validate:"required"`
+	TargetAmount Decimal `json:"target_amount" validate:"required"`
+	Type         string  `json:"type" validate:"required"`
+	// SourceAmount of Currency is converted at Rate, less Fee, into
+	// TargetAmount on exchange payments
+	SourceAmount *Decimal `json:"source_amount,omitempty"`
+	Rate         *Decimal `json:"rate,omitempty"`
+	Fee          *Decimal `json:"fee,omitempty"`
 }
 
 type CostBuyResponse struct {
-	ExpectedFee      string              `json:"expected_fee" validate:"required"`
+	ExpectedFee      Decimal             `json:"expected_fee" validate:"required"`
 	FundID           string              `json:"fund_id" validate:"required"`
 	PaymentBreakdown []*PaymentBreakdown `json:"payment_breakdown" validate:"required"`
 	Request          *OrderBuy           `json:"request" validate:"required"`
-	TotalCost        string              `json:"total_cost" validate:"required"`
+	TotalCost        Decimal             `json:"total_cost" validate:"required"`
 	Type             string              `json:"type" validate:"required"`
+	// ActingAsID is the account the quote was made for, Buy must act as it too
+	ActingAsID string `json:"-"`
+	// IdempotencyKey sent when buying this quote, reused if Buy is called again
+	IdempotencyKey string `json:"-"`
 }
 
 type CreateBuyRequest struct {
@@ -265,31 +341,231 @@ type CreateBuyRequest struct {
 	Order            *OrderBuy           `json:"order" validate:"required"`
 	IdempotencyKey   string              `json:"idempotency_key" validate:"required"`
 	PaymentBreakdown []*PaymentBreakdown `json:"payment_breakdown" validate:"required"`
-	ExpectedFee      string              `json:"expected_fee" validate:"required"`
+	ExpectedFee      Decimal             `json:"expected_fee" validate:"required"`
 }
 
 // Sell Transactions Types
 
 type CostSellRequest struct {
-	FundID     string `json:"fund_id" validate:"required"`
-	ActingAsID string `json:"acting_as_id" validate:"required"`
+	FundID     string     `json:"fund_id" validate:"required"`
+	ActingAsID string     `json:"acting_as_id" validate:"required"`
 	Order      *OrderSell `json:"order" validate:"required"`
 }
 
 type OrderSell struct {
-	Type           string `json:"type" validate:"required"`
-	ShareAmount    string `json:"share_amount" validate:"required"`
+	Type           string   `json:"type" validate:"required"`
+	ShareAmount    *Shares  `json:"share_amount,omitempty"`
+	CurrencyAmount *Decimal `json:"currency_amount,omitempty"`
+	PriceLimit     *Decimal `json:"price_limit,omitempty"`
+	ExpiryDate     *Date    `json:"expiry_date,omitempty"`
 }
 
 type CostSellResponse struct {
-	FundID  string `json:"fund_id" validate:"required"`
+	FundID  string     `json:"fund_id" validate:"required"`
 	Request *OrderSell `json:"request" validate:"required"`
-	Type    string `json:"type" validate:"required"`
+	Type    string     `json:"type" validate:"required"`
+	// ActingAsID is the account the quote was made for, Sell must act as it too
+	ActingAsID string `json:"-"`
+	// IdempotencyKey sent when selling this quote, reused if Sell is called again
+	IdempotencyKey string `json:"-"`
 }
 
 type CreateSellRequest struct {
-	FundID           string              `json:"fund_id" validate:"required"`
-	ActingAsID       string              `json:"acting_as_id" validate:"required"`
-	Order            *OrderSell           `json:"order" validate:"required"`
-	IdempotencyKey   string              `json:"idempotency_key" validate:"required"`
+	FundID         string     `json:"fund_id" validate:"required"`
+	ActingAsID     string     `json:"acting_as_id" validate:"required"`
+	Order          *OrderSell `json:"order" validate:"required"`
+	IdempotencyKey string     `json:"idempotency_key" validate:"required"`
+}
+
+// Order is a buy or sell order placed for an account
+type Order struct {
+	ID             string     `json:"id" validate:"required"`
+	FundID         string     `json:"fund_id" validate:"required"`
+	Side           string     `json:"side" validate:"required"`
+	Type           string     `json:"order_type" validate:"required"`
+	State          string     `json:"state" validate:"required"`
+	Currency       string     `json:"currency" validate:"required"`
+	CurrencyAmount *Decimal   `json:"currency_amount,omitempty"`
+	ShareAmount    *Shares    `json:"share_amount,omitempty"`
+	PriceLimit     *Decimal   `json:"price_limit,omitempty"`
+	ExpiryDate     *Date      `json:"expiry_date,omitempty"`
+	FillPrice      *Decimal   `json:"fill_price,omitempty"`
+	FilledShares   Shares     `json:"filled_shares"`
+	FilledAmount   Decimal    `json:"filled_amount"`
+	Fee            Decimal    `json:"fee"`
+	Created        time.Time  `json:"created" validate:"required"`
+	Filled         *time.Time `json:"filled,omitempty"`
+}
+
+type OrdersRequest struct {
+	ActingAsID string   `json:"acting_as_id" validate:"required"`
+	States     []string `json:"states,omitempty"`
+}
+
+type OrdersResponse struct {
+	Type   string   `json:"type" validate:"required"`
+	Orders []*Order `json:"orders" validate:"required"`
+}
+
+type OrderRequest struct {
+	ActingAsID string `json:"acting_as_id" validate:"required"`
+	OrderID    string `json:"order_id" validate:"required"`
+}
+
+type OrderResponse struct {
+	Type  string `json:"type" validate:"required"`
+	Order *Order `json:"order" validate:"required"`
+}
+
+// Transaction is a movement of the account wallet or holdings. Amount is
+// signed, negative when money leaves the wallet.
+type Transaction struct {
+	ID          string    `json:"id" validate:"required"`
+	Type        string    `json:"type" validate:"required"`
+	Timestamp   time.Time `json:"timestamp" validate:"required"`
+	Description string    `json:"description"`
+	Currency    string    `json:"currency" validate:"required"`
+	Amount      Decimal   `json:"amount" validate:"required"`
+	// Balance is the wallet balance in Currency after the transaction
+	Balance Decimal `json:"balance"`
+	FundID  string  `json:"fund_id,omitempty"`
+	OrderID string  `json:"order_id,omitempty"`
+	// Shares bought or sold, or held when a dividend was paid
+	Shares      *Shares  `json:"shares,omitempty"`
+	Price       *Decimal `json:"price,omitempty"`
+	Fee         Decimal  `json:"fee"`
+	TaxWithheld Decimal  `json:"tax_withheld"`
+	// ExchangeCurrency and ExchangeAmount are what an exchange converted Amount into
+	ExchangeCurrency string   `json:"exchange_currency,omitempty"`
+	ExchangeAmount   *Decimal `json:"exchange_amount,omitempty"`
+	ExchangeRate     *Decimal `json:"exchange_rate,omitempty"`
+}
+
+type TransactionsRequest struct {
+	ActingAsID string   `json:"acting_as_id" validate:"required"`
+	From       *Date    `json:"from,omitempty"`
+	To         *Date    `json:"to,omitempty"`
+	FundID     string   `json:"fund_id,omitempty"`
+	Types      []string `json:"types,omitempty"`
+	Before     string   `json:"before,omitempty"`
+	Limit      int      `json:"limit,omitempty"`
+}
+
+type TransactionsResponse struct {
+	Type         string         `json:"type" validate:"required"`
+	Transactions []*Transaction `json:"transactions" validate:"required"`
+	HasMore      bool           `json:"has_more"`
+}
+
+// Autoinvest Types
+
+const (
+	AutoinvestIntervalWeekly      = "weekly"
+	AutoinvestIntervalFortnightly = "fortnightly"
+	AutoinvestIntervalMonthly     = "monthly"
+
+	AutoinvestStateActive = "active"
+	AutoinvestStatePaused = "paused"
+)
+
+type AutoinvestRequest struct {
+	ActingAsID     string        `json:"acting_as_id" validate:"required"`
+	Allocations    []Allocations `json:"allocations,omitempty"`
+	PremadeOrderID string        `json:"premade_order_id,omitempty"`
+	Amount         Decimal       `json:"amount" validate:"required"`
+	Interval       string        `json:"interval" validate:"required"`
+	StartDate      *Date         `json:"start_date,omitempty"`
+}
+
+type AutoinvestActionRequest struct {
+	ActingAsID string `json:"acting_as_id" validate:"required"`
+}
+
+type AutoinvestResponse struct {
+	Type  string           `json:"type" validate:"required"`
+	Order *AutoinvestOrder `json:"order"`
+}
+
+type PremadeOrder struct {
+	ID          string        `json:"id" validate:"required"`
+	Name        string        `json:"name" validate:"required"`
+	Description string        `json:"description"`
+	Allocations []Allocations `json:"allocations" validate:"required"`
+}
+
+type PremadeOrdersResponse struct {
+	Type   string          `json:"type" validate:"required"`
+	Orders []*PremadeOrder `json:"orders" validate:"required"`
+}
+
+// Wallet Types
+
+type WalletRequest struct {
+	ActingAsID string `json:"acting_as_id" validate:"required"`
+}
+
+type WalletResponse struct {
+	Type                    string          `json:"type" validate:"required"`
+	WalletBalances          *WalletBalances `json:"wallet_balances" validate:"required"`
+	HoldingBalance          Decimal         `json:"holding_balance" validate:"required"`
+	MaximumWithdrawalAmount Decimal         `json:"maximum_withdrawal_amount" validate:"required"`
+	MinimumWalletBalance    Decimal         `json:"minimum_wallet_balance" validate:"required"`
+	AccountReference        string          `json:"account_reference" validate:"required"`
+}
+
+type DepositReferenceResponse struct {
+	Type        string `json:"type" validate:"required"`
+	Payee       string `json:"payee" validate:"required"`
+	BankAccount string `json:"bank_account" validate:"required"`
+	Reference   string `json:"reference" validate:"required"`
+}
+
+type WithdrawRequest struct {
+	ActingAsID     string  `json:"acting_as_id" validate:"required"`
+	Amount         Decimal `json:"amount" validate:"required"`
+	IdempotencyKey string  `json:"idempotency_key" validate:"required"`
+}
+
+type WithdrawResponse struct {
+	Type        string       `json:"type" validate:"required"`
+	Transaction *Transaction `json:"transaction" validate:"required"`
+}
+
+// Exchange Types
+
+type CostExchangeRequest struct {
+	ActingAsID     string  `json:"acting_as_id" validate:"required"`
+	SourceCurrency string  `json:"source_currency" validate:"required"`
+	TargetCurrency string  `json:"target_currency" validate:"required"`
+	SourceAmount   Decimal `json:"source_amount" validate:"required"`
+}
+
+type CostExchangeResponse struct {
+	Type           string  `json:"type" validate:"required"`
+	SourceCurrency string  `json:"source_currency" validate:"required"`
+	TargetCurrency string  `json:"target_currency" validate:"required"`
+	SourceAmount   Decimal `json:"source_amount" validate:"required"`
+	// TargetAmount is what SourceAmount converts into at Rate, less Fee
+	TargetAmount Decimal `json:"target_amount" validate:"required"`
+	Rate         Decimal `json:"rate" validate:"required"`
+	Fee          Decimal `json:"fee" validate:"required"`
+	// ActingAsID is the account the quote was made for, Exchange must act as it too
+	ActingAsID string `json:"-"`
+	// IdempotencyKey sent when exchanging this quote, reused if Exchange is called again
+	IdempotencyKey string `json:"-"`
+}
+
+type CreateExchangeRequest struct {
+	ActingAsID     string  `json:"acting_as_id" validate:"required"`
+	SourceCurrency string  `json:"source_currency" validate:"required"`
+	TargetCurrency string  `json:"target_currency" validate:"required"`
+	SourceAmount   Decimal `json:"source_amount" validate:"required"`
+	// ExpectedTargetAmount is rejected if the rate has moved since the quote
+	ExpectedTargetAmount Decimal `json:"expected_target_amount" validate:"required"`
+	IdempotencyKey       string  `json:"idempotency_key" validate:"required"`
+}
+
+type ExchangeResponse struct {
+	Type        string       `json:"type" validate:"required"`
+	Transaction *Transaction `json:"transaction" validate:"required"`
 }
@@ -0,0 +1,29 @@
+package sharesies_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+func Test_Date_JSON(t *testing.T) {
+	d := sharesies.Date{Year: 2021, Month: time.March, Day: 31}
+
+	b, err := json.Marshal(&sharesies.OrderSell{Type: sharesies.OrderTypeShareLimit, ExpiryDate: &d})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"type":"share_limit","expiry_date":"2021-03-31"}`, string(b))
+
+	var got sharesies.Date
+	assert.Nil(t, json.Unmarshal([]byte(`"2021-03-31T23:00:00Z"`), &got))
+	assert.Equal(t, d, got)
+	assert.Equal(t, "2021-04-01", got.AddDays(1).String())
+}
+
+func Test_Today(t *testing.T) {
+	assert.Equal(t, "Pacific/Auckland", sharesies.NZ.String())
+	assert.Equal(t, sharesies.NewDate(time.Now().In(sharesies.NZ)), sharesies.Today())
+}
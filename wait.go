@@ -0,0 +1,93 @@
+package sharesies
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultPollInterval is the first delay between two polls of WaitForOrder
+	DefaultPollInterval = time.Second
+	// DefaultMaxPollInterval caps the delay between two polls of WaitForOrder
+	DefaultMaxPollInterval = 30 * time.Second
+)
+
+// WaitOptions configures WaitForOrder, the zero value uses the defaults
+type WaitOptions struct {
+	// PollInterval is the first delay between two polls, doubled after every poll
+	PollInterval time.Duration
+	// MaxPollInterval caps the delay between two polls
+	MaxPollInterval time.Duration
+	// Updates receives the order every time its state changes, including the
+	// final one. WaitForOrder blocks sending to it, so it must be drained or buffered.
+	Updates chan<- *Order
+}
+
+// WaitForOrder polls the order with id until it is fulfilled, cancelled,
+// rejected or expired and returns it in that final state
+func (s *Sharesies) WaitForOrder(ctx context.Context, id string, opts *WaitOptions) (*Order, error) {
+	return s.account().WaitForOrder(ctx, id, opts)
+}
+
+// WaitForOrder polls the order with id until it is fulfilled, cancelled,
+// rejected or expired and returns it in that final state. When ctx is done
+// first the last order seen is returned with the context error.
+func (a *Account) WaitForOrder(ctx context.Context, id string, opts *WaitOptions) (*Order, error) {
+	if opts == nil {
+		opts = &WaitOptions{}
+	}
+
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	maxInterval := opts.MaxPollInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultMaxPollInterval
+	}
+
+	var last *Order
+	for {
+		o, err := a.Order(ctx, id)
+		if err != nil {
+			return last, err
+		}
+
+		if last == nil || last.State != o.State {
+			if err := opts.send(ctx, o); err != nil {
+				return o, err
+			}
+		}
+
+		last = o
+		if o.IsTerminal() {
+			return o, nil
+		}
+
+		t := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return last, ctx.Err()
+		case <-t.C:
+		}
+
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+func (o *WaitOptions) send(ctx context.Context, order *Order) error {
+	if o.Updates == nil {
+		return nil
+	}
+
+	select {
+	case o.Updates <- order:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,79 @@
+package sharesies_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+)
+
+func Test_WaitForOrder(t *testing.T) {
+	srv, _, s := newFakeServer(t)
+	srv.SetFillDelay(20 * time.Millisecond)
+	ctx := context.Background()
+
+	costBuy, _ := s.CostBuy(ctx, fakeFundID, sharesies.NewDecimal(50, 0))
+	p, err := s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+	assert.Equal(t, sharesies.OrderStateProcessing, p.PlacedOrder(nil, fakeFundID, sharesies.OrderSideBuy).State)
+
+	updates := make(chan *sharesies.Order, 10)
+	o, err := s.WaitForOrder(ctx, p.PlacedOrder(nil, fakeFundID, sharesies.OrderSideBuy).ID, &sharesies.WaitOptions{
+		PollInterval:    time.Millisecond,
+		MaxPollInterval: 5 * time.Millisecond,
+		Updates:         updates,
+	})
+	close(updates)
+
+	assert.Nil(t, err)
+	assert.Equal(t, sharesies.OrderStateFulfilled, o.State)
+	assert.Equal(t, "2.00", o.FillPrice.String())
+	assert.Equal(t, "24.875000", o.FilledShares.String())
+
+	states := []string{}
+	for u := range updates {
+		states = append(states, u.State)
+	}
+	assert.Equal(t, []string{sharesies.OrderStateProcessing, sharesies.OrderStateFulfilled}, states)
+}
+
+func Test_WaitForOrder_ContextDone(t *testing.T) {
+	_, _, s := newFakeServer(t)
+
+	costBuy, _ := s.CostBuyLimit(context.Background(), &sharesies.LimitOrder{
+		FundID:     fakeFundID,
+		Shares:     sharesies.MustParseShares("10"),
+		PriceLimit: sharesies.MustParseDecimal("1.50"),
+		GoodTill:   sharesies.Today(),
+	})
+	p, err := s.Buy(context.Background(), costBuy)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	o, err := s.WaitForOrder(ctx, p.PlacedOrder(nil, fakeFundID, sharesies.OrderSideBuy).ID, &sharesies.WaitOptions{PollInterval: time.Millisecond})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, sharesies.OrderStatePending, o.State)
+}
+
+func Test_WaitForOrder_NoOrder(t *testing.T) {
+	srv, _, s := newFakeServer(t)
+	ctx := context.Background()
+
+	costBuy, _ := s.CostBuy(ctx, fakeFundID, sharesies.NewDecimal(50, 0))
+	p, err := s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+
+	srv.Fail("/api/order/get", http.StatusOK, 1)
+
+	o, err := s.WaitForOrder(ctx, p.PlacedOrder(nil, fakeFundID, sharesies.OrderSideBuy).ID, &sharesies.WaitOptions{PollInterval: time.Millisecond})
+
+	assert.ErrorIs(t, err, sharesies.ErrNoOrder)
+	assert.Nil(t, o)
+}
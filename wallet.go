@@ -0,0 +1,160 @@
+package sharesies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidWithdrawal = errors.New("invalid withdrawal")
+
+// Wallet is the cash held by an account
+type Wallet struct {
+	// Balances are keyed by lower case currency, such as "nzd"
+	Balances map[string]Money
+	// HoldingBalance is the NZD held for orders being processed
+	HoldingBalance Money
+	// MaximumWithdrawal is the most NZD that can be withdrawn now
+	MaximumWithdrawal Money
+	MinimumBalance    Money
+	// AccountReference identifies the account on bank deposits
+	AccountReference string
+}
+
+// Balance returns the balance of currency, zero if the wallet holds none
+func (w *Wallet) Balance(currency string) Money {
+	currency = strings.ToLower(currency)
+	if m, ok := w.Balances[currency]; ok {
+		return m
+	}
+
+	return NewMoney(Decimal{}, currency)
+}
+
+// Covers reports whether the wallet holds enough to pay for costBuy, the
+// payments drawing on each currency are added up before comparing
+func (w *Wallet) Covers(costBuy *CostBuyResponse) bool {
+	for currency, amount := range costBuy.Payments() {
+		if w.Balance(currency).Amount.LessThan(amount) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Payments returns what each wallet currency pays for the buy, an exchange
+// takes SourceAmount of Currency while TargetAmount is in the fund's currency
+func (c *CostBuyResponse) Payments() map[string]Decimal {
+	payments := map[string]Decimal{}
+	for _, p := range c.PaymentBreakdown {
+		amount := p.TargetAmount
+		if p.IsExchange() && p.SourceAmount != nil {
+			amount = *p.SourceAmount
+		}
+
+		currency := strings.ToLower(p.Currency)
+		payments[currency] = payments[currency].Add(amount)
+	}
+
+	return payments
+}
+
+// Wallet returns the balances of the account
+func (s *Sharesies) Wallet(ctx context.Context) (*Wallet, error) {
+	return s.account().Wallet(ctx)
+}
+
+// Deposits returns the deposits made between from and to, newest first, zero dates are open ended
+func (s *Sharesies) Deposits(ctx context.Context, from, to Date) ([]*Transaction, error) {
+	return s.account().Deposits(ctx, from, to)
+}
+
+// DepositReference returns the bank account and reference to deposit money into the wallet with
+func (s *Sharesies) DepositReference(ctx context.Context) (*DepositReferenceResponse, error) {
+	return s.account().DepositReference(ctx)
+}
+
+// Withdraw requests a withdrawal of amount NZD to the bank account of the account
+func (s *Sharesies) Withdraw(ctx context.Context, amount Decimal) (*Transaction, error) {
+	return s.account().Withdraw(ctx, amount)
+}
+
+// Wallet returns the balances of the account
+func (a *Account) Wallet(ctx context.Context) (*Wallet, error) {
+	actingAsID, err := a.actingAsID()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &WalletResponse{}
+	err = a.s.authRequest(ctx, http.MethodPost, a.s.endpoints().Wallet, false, &WalletRequest{ActingAsID: actingAsID}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Wallet{
+		Balances:          map[string]Money{},
+		HoldingBalance:    NewMoney(r.HoldingBalance, "nzd"),
+		MaximumWithdrawal: NewMoney(r.MaximumWithdrawalAmount, "nzd"),
+		MinimumBalance:    NewMoney(r.MinimumWalletBalance, "nzd"),
+		AccountReference:  r.AccountReference,
+	}
+
+	if r.WalletBalances != nil {
+		for _, currency := range walletCurrencies {
+			w.Balances[currency] = r.WalletBalances.Balance(currency)
+		}
+	}
+
+	return w, nil
+}
+
+// Deposits returns the deposits made between from and to, newest first, zero dates are open ended
+func (a *Account) Deposits(ctx context.Context, from, to Date) ([]*Transaction, error) {
+	return a.Transactions(ctx, &TransactionFilter{From: from, To: to, Types: []string{TransactionTypeDeposit}})
+}
+
+// DepositReference returns the bank account and reference to deposit money into the wallet with
+func (a *Account) DepositReference(ctx context.Context) (*DepositReferenceResponse, error) {
+	actingAsID, err := a.actingAsID()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &DepositReferenceResponse{}
+	err = a.s.authRequest(ctx, http.MethodPost, a.s.endpoints().DepositReference, false, &WalletRequest{ActingAsID: actingAsID}, r)
+	return r, err
+}
+
+// Withdraw requests a withdrawal of amount NZD to the bank account of the
+// account, checked against the maximum withdrawal amount first
+func (a *Account) Withdraw(ctx context.Context, amount Decimal) (*Transaction, error) {
+	if amount.Sign() <= 0 || !amount.Round(2).Equal(amount) {
+		return nil, fmt.Errorf("%w: amount must be positive with at most 2 decimal places", ErrInvalidWithdrawal)
+	}
+
+	w, err := a.Wallet(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if amount.GreaterThan(w.MaximumWithdrawal.Amount) {
+		return nil, fmt.Errorf("%w: %s is more than the maximum withdrawal of %s", ErrInvalidWithdrawal, amount, w.MaximumWithdrawal)
+	}
+
+	actingAsID, err := a.actingAsID()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &WithdrawResponse{}
+	req := &WithdrawRequest{ActingAsID: actingAsID, Amount: amount, IdempotencyKey: uuid.NewString()}
+
+	err = a.s.authRequest(ctx, http.MethodPost, a.s.endpoints().Withdraw, false, req, r)
+	return r.Transaction, err
+}
@@ -0,0 +1,121 @@
+package sharesies_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/deividfortuna/sharesies"
+	"github.com/deividfortuna/sharesies/sharesiestest"
+)
+
+func Test_Wallet_Covers(t *testing.T) {
+	w := &sharesies.Wallet{Balances: map[string]sharesies.Money{
+		"nzd": sharesies.NewMoney(sharesies.NewDecimal(100, 0), "nzd"),
+	}}
+
+	cost := func(currency string, amount int64) *sharesies.CostBuyResponse {
+		return &sharesies.CostBuyResponse{PaymentBreakdown: []*sharesies.PaymentBreakdown{
+			{Currency: currency, TargetAmount: sharesies.NewDecimal(amount, 0)},
+		}}
+	}
+
+	assert.True(t, w.Covers(cost("nzd", 100)))
+	assert.False(t, w.Covers(cost("nzd", 101)))
+	assert.False(t, w.Covers(cost("usd", 1)))
+	assert.True(t, w.Balance("USD").Amount.IsZero())
+
+	split := cost("nzd", 60)
+	split.PaymentBreakdown = append(split.PaymentBreakdown, split.PaymentBreakdown[0])
+	assert.False(t, w.Covers(split))
+}
+
+func Test_WalletBalances_Balance(t *testing.T) {
+	w := &sharesies.WalletBalances{Nzd: sharesies.NewDecimal(100, 0), Usd: sharesies.NewDecimal(20, 0)}
+
+	assert.True(t, sharesies.NewDecimal(100, 0).Equal(w.Balance("NZD").Amount))
+	assert.True(t, sharesies.NewDecimal(20, 0).Equal(w.Balance("usd").Amount))
+
+	gbp := w.Balance("gbp")
+	assert.Equal(t, "gbp", gbp.Currency)
+	assert.True(t, gbp.Amount.IsZero())
+}
+
+func Test_Wallet_Covers_Exchange(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/costbuy.json")
+	assert.Nil(t, err)
+
+	costBuy := &sharesies.CostBuyResponse{}
+	assert.Nil(t, json.Unmarshal(b, costBuy))
+
+	wallet := func(nzd, usd string) *sharesies.Wallet {
+		return &sharesies.Wallet{Balances: map[string]sharesies.Money{
+			"nzd": sharesies.NewMoney(sharesies.MustParseDecimal(nzd), "nzd"),
+			"usd": sharesies.NewMoney(sharesies.MustParseDecimal(usd), "usd"),
+		}}
+	}
+
+	// 0.11 USD paid directly and 14.02 NZD exchanged into 9.89 USD
+	assert.True(t, wallet("14.02", "0.11").Covers(costBuy))
+	assert.False(t, wallet("14.01", "0.11").Covers(costBuy))
+	assert.False(t, wallet("10.00", "0.11").Covers(costBuy))
+	assert.False(t, wallet("14.02", "0.10").Covers(costBuy))
+}
+
+func Test_Withdraw_Invalid(t *testing.T) {
+	s, _ := sharesies.New(nil)
+
+	for _, amount := range []string{"0", "-5", "10.005"} {
+		_, err := s.Withdraw(context.Background(), sharesies.MustParseDecimal(amount))
+		assert.True(t, errors.Is(err, sharesies.ErrInvalidWithdrawal), amount)
+	}
+}
+
+func Test_Wallet(t *testing.T) {
+	srv, acc, s := newFakeServer(t)
+	ctx := context.Background()
+
+	srv.AddInstrument(&sharesies.Company{ID: "limit-fund", Symbol: "LIM", Marketprice: sharesies.MustParseDecimal("2.00"), Exchange: "NZX", Exchangecountry: "nzl"})
+	costBuy, err := s.CostBuyLimit(ctx, &sharesies.LimitOrder{
+		FundID:     "limit-fund",
+		Exchange:   "NZX",
+		Shares:     sharesies.MustParseShares("10"),
+		PriceLimit: sharesies.MustParseDecimal("1.50"),
+		GoodTill:   sharesies.Today().AddDays(7),
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = s.Buy(ctx, costBuy)
+	assert.Nil(t, err)
+
+	w, err := s.Wallet(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "84.92", w.Balance("nzd").Amount.String())
+	assert.Equal(t, "15.08", w.HoldingBalance.Amount.String())
+	assert.Equal(t, "84.92", w.MaximumWithdrawal.Amount.String())
+	assert.NotEmpty(t, w.AccountReference)
+
+	ref, err := s.DepositReference(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, w.AccountReference, ref.Reference)
+	assert.Equal(t, sharesiestest.DepositBankAccount, ref.BankAccount)
+
+	_, err = s.Withdraw(ctx, sharesies.MustParseDecimal("84.93"))
+	assert.True(t, errors.Is(err, sharesies.ErrInvalidWithdrawal))
+
+	tx, err := s.Withdraw(ctx, sharesies.NewDecimal(30, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, sharesies.TransactionTypeWithdrawal, tx.Type)
+	assert.Equal(t, "-30", tx.Amount.String())
+	assert.Equal(t, "54.92", acc.Balance("nzd").Round(2).String())
+
+	deposits, err := s.Deposits(ctx, sharesies.Date{}, sharesies.Date{})
+	assert.Nil(t, err)
+	assert.Len(t, deposits, 1)
+	assert.Equal(t, "100", deposits[0].Amount.String())
+}